@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/iancoffey/migratron/migrate"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	manifestPath string
+	stopOnError  bool
+)
+
+func init() {
+	batchCmd.Flags().StringVar(&manifestPath, "manifest", "", "YAML manifest listing the repo pairs to migrate")
+	batchCmd.Flags().BoolVar(&assumeYes, "yes", false, "start each repo pair without confirming its run summary")
+	batchCmd.Flags().BoolVar(&stopOnError, "stop-on-error", false, "stop at the first repo pair that fails instead of continuing with the next")
+
+	RootCmd.AddCommand(batchCmd)
+}
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "migrate the issues of every repo pair listed in a manifest",
+	RunE:  batch,
+}
+
+// manifestEntry is one repo pair of a batch manifest. Unset fields fall back to the flags.
+type manifestEntry struct {
+	From          string            `mapstructure:"from"`
+	To            string            `mapstructure:"to"`
+	LabelMap      map[string]string `mapstructure:"label_map"`
+	Blocklist     []string          `mapstructure:"blocklist"`
+	IncludeClosed *bool             `mapstructure:"include_closed"`
+	Since         string            `mapstructure:"since"`
+}
+
+// readManifest reads the entries of the repos key of a manifest
+func readManifest(path string) ([]manifestEntry, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	var entries []manifestEntry
+	if err := v.UnmarshalKey("repos", &entries); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: %s lists no repos", migrate.ErrInvalidConfig, path)
+	}
+	return entries, nil
+}
+
+// entryConfig builds the migrate.Config and options for one manifest entry
+func entryConfig(cmd *cobra.Command, rep *reporter, e manifestEntry) (migrate.Config, migrate.AllOptions, error) {
+	var opts migrate.AllOptions
+	from, err := migrate.ParseRepo(e.From)
+	if err != nil {
+		return migrate.Config{}, opts, fmt.Errorf("from: %w", err)
+	}
+	to, err := migrate.ParseRepo(e.To)
+	if err != nil {
+		return migrate.Config{}, opts, fmt.Errorf("to: %w", err)
+	}
+	cfg, err := clientConfig()
+	if err != nil {
+		return cfg, opts, err
+	}
+	cfg.From, cfg.To = from, to
+	cfg, err = withMigrateFlags(cmd, rep, cfg)
+	if err != nil {
+		return cfg, opts, err
+	}
+	if e.LabelMap != nil {
+		cfg.LabelMap = e.LabelMap
+	}
+	if e.Blocklist != nil {
+		// entry terms add to the defaults and --blocklist-file, never replace them
+		base := cfg.Blocklist
+		if base == nil {
+			base = migrate.DefaultBlocklist
+		}
+		cfg.Blocklist = append(append([]string{}, base...), e.Blocklist...)
+	}
+
+	opts.IncludeClosed = includeClosed
+	opts.Yes = assumeYes
+	if e.IncludeClosed != nil {
+		opts.IncludeClosed = *e.IncludeClosed
+	}
+	opts.Since, err = parseDate(e.Since)
+	return cfg, opts, err
+}
+
+func batch(cmd *cobra.Command, args []string) error {
+	if manifestPath == "" {
+		return fmt.Errorf("%w: --manifest must be set", migrate.ErrInvalidConfig)
+	}
+	entries, err := readManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	rep, err := newReporter(cmd)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	started := time.Now()
+	var stats migrate.Stats
+	for _, e := range entries {
+		cmd.Printf("\n=== %s -> %s ===\n", e.From, e.To)
+		cfg, opts, err := entryConfig(cmd, rep, e)
+		if err == nil {
+			var results []migrate.Result
+			m := migrate.New(cfg)
+			results, err = m.MigrateAll(context.Background(), opts)
+			stats.Add(m.Stats())
+			cmd.Printf("%s -> %s: %d issues processed\n", e.From, e.To, len(results))
+		}
+		if err == nil {
+			continue
+		}
+		failed++
+		cmd.Printf("%s -> %s failed: %s\n", e.From, e.To, errorMessage(err))
+		if stopOnError || errors.Is(err, migrate.ErrUserAborted) {
+			rep.Close()
+			return err
+		}
+	}
+
+	rep.printStats(stats, time.Since(started))
+	if err := rep.Close(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d repo pairs failed", failed, len(entries))
+	}
+	if err := runPostHook(cmd); err != nil {
+		return err
+	}
+	cmd.Println("Completed all repo pairs!")
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/iancoffey/migratron/migrate"
+	"github.com/spf13/viper"
+)
+
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+var (
+	insecureSkipVerify         bool
+	traceRequests              bool
+	requestTimeout             time.Duration
+	sourceBaseURL, destBaseURL string
+)
+
+func init() {
+	RootCmd.PersistentFlags().BoolVar(&traceRequests, "trace", false, "log the method, path and status of every GitHub API request")
+	RootCmd.PersistentFlags().BoolVar(&insecureSkipVerify, "insecure-skip-verify", false, "skip TLS certificate verification, for GitHub Enterprise servers with self-signed certificates")
+	RootCmd.PersistentFlags().StringVar(&sourceBaseURL, "source-base-url", "", "API root of a GitHub Enterprise Server source, like https://ghe.example.com/api/v3/; MIGRATRON_SOURCE_TOKEN authenticates it if set")
+	RootCmd.PersistentFlags().StringVar(&destBaseURL, "dest-base-url", "", "API root of a GitHub Enterprise Server target, like https://ghe.example.com/api/v3/")
+	RootCmd.PersistentFlags().DurationVar(&requestTimeout, "timeout", 30*time.Second, "timeout for each GitHub API request")
+}
+
+// newHTTPClient builds the client GitHub requests are sent with. Requests go
+// through HTTPS_PROXY/HTTP_PROXY/NO_PROXY when they are set.
+func newHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if insecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	var rt http.RoundTripper = transport
+	if traceRequests {
+		rt = tracingTransport{base: transport}
+	}
+	return &http.Client{
+		Transport: rt,
+		Timeout:   requestTimeout,
+	}
+}
+
+// clientConfig builds the connection settings of a migrate.Config from flags and env
+func clientConfig() (migrate.Config, error) {
+	cfg := migrate.Config{
+		Token:       viper.GetString("TOKEN"),
+		SourceToken: viper.GetString("SOURCE_TOKEN"),
+		HTTPClient:  newHTTPClient(),
+		UserAgent:   userAgent(),
+	}
+	var err error
+	if cfg.SourceBaseURL, err = parseBaseURL("--source-base-url", sourceBaseURL); err != nil {
+		return cfg, err
+	}
+	cfg.TargetBaseURL, err = parseBaseURL("--dest-base-url", destBaseURL)
+	return cfg, err
+}
+
+// parseBaseURL parses the API root given to flag, nil when it is empty
+func parseBaseURL(flag, s string) (*url.URL, error) {
+	if s == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("%w: %s must be an absolute URL, got %q", migrate.ErrInvalidConfig, flag, s)
+	}
+	return u, nil
+}
+
+// userAgent identifies migratron and its version to GitHub
+func userAgent() string {
+	return "migratron/" + version
+}
+
+// tracingTransport logs each request to stderr. Only the method, path and
+// status are logged, never headers or bodies, so tokens stay out of the log.
+type tracingTransport struct {
+	base http.RoundTripper
+}
+
+func (t tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "trace: %s %s: %v (%s)\n", req.Method, req.URL.Path, err, elapsed)
+		return resp, err
+	}
+	fmt.Fprintf(os.Stderr, "trace: %s %s %d (%s)\n", req.Method, req.URL.Path, resp.StatusCode, elapsed)
+	return resp, nil
+}
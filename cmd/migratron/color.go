@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+
+	"github.com/manifoldco/promptui"
+)
+
+var noColor bool
+
+func init() {
+	RootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output, also disabled by NO_COLOR or when stdout is not a terminal")
+}
+
+// colorEnabled reports whether output may contain ANSI colors
+func colorEnabled() bool {
+	if noColor {
+		return false
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	fi, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// plainTemplates renders promptui prompts without colors. promptui fills in
+// the templates it is given, so each prompt needs its own copy.
+func plainTemplates() *promptui.PromptTemplates {
+	return &promptui.PromptTemplates{
+		Prompt:          "? {{ . }}: ",
+		Confirm:         "? {{ . }}? [y/N] ",
+		Valid:           "✔ {{ . }}: ",
+		Invalid:         "✗ {{ . }}: ",
+		ValidationError: ">> {{ . }}",
+		Success:         "{{ . }}: ",
+	}
+}
+
+// plainSelectTemplates renders promptui selects without colors
+func plainSelectTemplates() *promptui.SelectTemplates {
+	return &promptui.SelectTemplates{
+		Label:    "{{ . }}:",
+		Active:   "> {{ . }}",
+		Inactive: "  {{ . }}",
+		Selected: "{{ . }}",
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/google/go-github/v36/github"
+	"github.com/iancoffey/migratron/migrate"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/viper"
+)
+
+// accessibleRepos caches the repos listed for selection, by "source" or
+// "target", so picking both with the same client lists them once
+var accessibleRepos = map[string][]string{}
+
+// configuredRepo parses the repo set in the env var bound to key, named env.
+// When it is unset and stdin is a terminal, the repo is picked from those
+// the token can access, with the source client when source is set.
+func configuredRepo(cfg migrate.Config, key, env, label string, source bool) (migrate.Repo, error) {
+	s := viper.GetString(key)
+	if s == "" && stdinIsTerminal() {
+		m := migrate.New(cfg)
+		client, cache := m.Client(), "target"
+		if source && (cfg.SourceBaseURL != nil || cfg.SourceToken != "") {
+			client, cache = m.SourceClient(), "source"
+		}
+		return selectRepo(client, cache, label)
+	}
+	r, err := migrate.ParseRepo(s)
+	if err != nil {
+		return r, fmt.Errorf("%s env: %w", env, err)
+	}
+	return r, nil
+}
+
+// selectRepo lets the user pick one of the repos client can access, caching
+// them under cache
+func selectRepo(client *github.Client, cache, label string) (migrate.Repo, error) {
+	repos, ok := accessibleRepos[cache]
+	if !ok {
+		var err error
+		if repos, err = listAccessibleRepos(client); err != nil {
+			return migrate.Repo{}, err
+		}
+		if len(repos) == 0 {
+			return migrate.Repo{}, fmt.Errorf("%w: no repo set and the token can not access any", migrate.ErrBadRepoFormat)
+		}
+		accessibleRepos[cache] = repos
+	}
+
+	prompt := promptui.Select{
+		Label: label,
+		Items: repos,
+		Size:  15,
+	}
+	if !colorEnabled() {
+		prompt.Templates = plainSelectTemplates()
+	}
+	_, name, err := prompt.Run()
+	if err != nil {
+		return migrate.Repo{}, promptError(err)
+	}
+	return migrate.ParseRepo(name)
+}
+
+// listAccessibleRepos lists the full names of the repos the token can access,
+// including those of the orgs it is a member of
+func listAccessibleRepos(client *github.Client) ([]string, error) {
+	var names []string
+	opts := &github.RepositoryListOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		repos, resp, err := client.Repositories.List(context.Background(), "", opts)
+		if err != nil {
+			return nil, &migrate.APIError{Op: "list repos", Err: err}
+		}
+		for _, r := range repos {
+			names = append(names, r.GetFullName())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// stdinIsTerminal reports whether the user can answer prompts
+func stdinIsTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/go-github/v36/github"
+	"github.com/iancoffey/migratron/migrate"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// minRateRemaining is the core rate limit headroom doctor expects for a migration run
+const minRateRemaining = 500
+
+var errDoctorFailed = errors.New("doctor checks failed")
+
+func init() {
+	RootCmd.AddCommand(doctorCmd)
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "check the token, repos and rate limit a migration depends on",
+	RunE:  doctor,
+}
+
+// doctorCheck is one line of the doctor checklist
+type doctorCheck struct {
+	name string
+	run  func(ctx context.Context) (string, error)
+}
+
+func doctor(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	out := cmd.OutOrStdout()
+	good, bad := "✔", "✗"
+	if colorEnabled() {
+		good, bad = "\x1b[32m✔\x1b[0m", "\x1b[31m✗\x1b[0m"
+	}
+	failed := 0
+	check := func(name, detail string, err error) {
+		if err != nil {
+			failed++
+			fmt.Fprintf(out, "%s %s: %v\n", bad, name, err)
+			return
+		}
+		fmt.Fprintf(out, "%s %s: %s\n", good, name, detail)
+	}
+
+	cfg, err := repoConfig()
+	if err == nil && cfg.Token == "" {
+		err = fmt.Errorf("%s is not set", tokenEnv)
+	}
+	check("configuration", fmt.Sprintf("token set, %s=%s, %s=%s", fromRepoEnv, viper.GetString("FROM_REPO"), toRepoEnv, viper.GetString("TO_REPO")), err)
+	if err != nil {
+		return errDoctorFailed
+	}
+	m := migrate.New(cfg)
+	client, sourceClient := m.Client(), m.SourceClient()
+
+	var source, target *github.Repository
+	checks := []doctorCheck{
+		{"token", func(ctx context.Context) (string, error) {
+			user, resp, err := client.Users.Get(ctx, "")
+			if err != nil {
+				return "", err
+			}
+			scopes := resp.Header.Get("X-OAuth-Scopes")
+			if scopes == "" {
+				return "authenticated as " + user.GetLogin() + ", no scopes reported (fine-grained token)", nil
+			}
+			if !migrate.HasScope(scopes, "public_repo") {
+				return "", fmt.Errorf("authenticated as %s but scopes %q lack repo or public_repo", user.GetLogin(), scopes)
+			}
+			return "authenticated as " + user.GetLogin() + ", scopes " + scopes, nil
+		}},
+		{"source read access", func(ctx context.Context) (string, error) {
+			source, _, err = sourceClient.Repositories.Get(ctx, cfg.From.Owner, cfg.From.Name)
+			if err != nil {
+				return "", err
+			}
+			return cfg.From.String(), nil
+		}},
+		{"target write access", func(ctx context.Context) (string, error) {
+			var resp *github.Response
+			target, resp, err = client.Repositories.Get(ctx, cfg.To.Owner, cfg.To.Name)
+			if err != nil {
+				return "", err
+			}
+			if scopes := resp.Header.Get("X-OAuth-Scopes"); scopes != "" && !migrate.HasScope(scopes, migrate.RequiredScope(target)) {
+				return "", fmt.Errorf("%s needs the %s scope, the token has %q", cfg.To, migrate.RequiredScope(target), scopes)
+			}
+			if !target.GetPermissions()["push"] {
+				return "", fmt.Errorf("token can not push to %s", cfg.To)
+			}
+			return cfg.To.String(), nil
+		}},
+		{"issues enabled", func(ctx context.Context) (string, error) {
+			if source == nil || target == nil {
+				return "", errors.New("both repos must be readable")
+			}
+			if !source.GetHasIssues() {
+				return "", fmt.Errorf("%w on %s", migrate.ErrIssuesDisabled, cfg.From)
+			}
+			if !target.GetHasIssues() {
+				return "", fmt.Errorf("%w on %s", migrate.ErrIssuesDisabled, cfg.To)
+			}
+			return "on both repos", nil
+		}},
+		{"rate limit", func(ctx context.Context) (string, error) {
+			limits, _, err := client.RateLimits(ctx)
+			if err != nil {
+				return "", err
+			}
+			core := limits.Core
+			if core == nil {
+				return "", errors.New("no core rate limit reported")
+			}
+			if core.Remaining < minRateRemaining {
+				return "", fmt.Errorf("%d of %d requests left, resets at %s", core.Remaining, core.Limit, core.Reset.Format("15:04:05"))
+			}
+			return fmt.Sprintf("%d of %d requests left", core.Remaining, core.Limit), nil
+		}},
+	}
+	for _, c := range checks {
+		detail, err := c.run(ctx)
+		check(c.name, detail, err)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d", errDoctorFailed, failed, len(checks)+1)
+	}
+	return nil
+}
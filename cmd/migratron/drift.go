@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/iancoffey/migratron/migrate"
+	"github.com/spf13/cobra"
+)
+
+var driftApply bool
+
+func init() {
+	driftIssuesCmd.Flags().BoolVar(&driftApply, "apply", false, "re-sync the title and body of drifted target issues from their source issues")
+	driftIssuesCmd.Flags().StringVar(&blocklistFile, "blocklist-file", "", "file of extra internal terms, one per line, # for comments and re: for regular expressions")
+
+	IssuesCmd.AddCommand(driftIssuesCmd)
+}
+
+var driftIssuesCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "report migrated issues whose source title or body changed since",
+	RunE:  driftIssues,
+}
+
+func driftIssues(cmd *cobra.Command, args []string) error {
+	if err := checkCommands(); err != nil {
+		return err
+	}
+	cfg, err := repoConfig()
+	if err != nil {
+		return err
+	}
+	if cfg, err = withBlocklistFile(cfg); err != nil {
+		return err
+	}
+	cfg = withContentFlags(cfg)
+
+	drifts, err := migrate.New(cfg).Drift(context.Background(), driftApply)
+	for _, d := range drifts {
+		var changed []string
+		if d.Title {
+			changed = append(changed, "title")
+		}
+		if d.Body {
+			changed = append(changed, "body")
+		}
+		cmd.Printf("drifted: %s -> %s (%s)\n", d.SourceURL, d.DestURL, strings.Join(changed, ", "))
+		switch {
+		case d.Blocked != "":
+			cmd.Printf("  not re-synced, the source contains %q\n", d.Blocked)
+		case d.Refused != "":
+			cmd.Printf("  not re-synced, %s\n", d.Refused)
+		case d.Applied:
+			cmd.Println("  re-synced")
+		}
+	}
+	return err
+}
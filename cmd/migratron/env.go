@@ -0,0 +1,11 @@
+package main
+
+// Env vars the token and repos are read from, for CI systems that expose
+// their secrets under other names, like GITHUB_TOKEN
+var tokenEnv, fromRepoEnv, toRepoEnv string
+
+func init() {
+	RootCmd.PersistentFlags().StringVar(&tokenEnv, "token-env", "MIGRATRON_TOKEN", "env var holding the GitHub token")
+	RootCmd.PersistentFlags().StringVar(&fromRepoEnv, "from-env", "MIGRATRON_FROM_REPO", "env var holding the source org/repo")
+	RootCmd.PersistentFlags().StringVar(&toRepoEnv, "to-env", "MIGRATRON_TO_REPO", "env var holding the target org/repo")
+}
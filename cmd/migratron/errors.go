@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/iancoffey/migratron/migrate"
+)
+
+// Exit codes returned by the CLI so scripts can tell failures apart
+const (
+	exitOK         = 0
+	exitError      = 1
+	exitValidation = 2
+	exitAPI        = 3
+	exitAborted    = 4
+	exitBudget     = 5
+)
+
+// Validation errors raised by the CLI itself
+var (
+	ErrBadIssueNumber = errors.New("invalid issue number")
+	ErrBadDate        = errors.New("invalid date")
+)
+
+var validationErrors = []error{
+	migrate.ErrMissingLogin,
+	migrate.ErrBadRepoFormat,
+	migrate.ErrInvalidConfig,
+	migrate.ErrTargetNotEmpty,
+	migrate.ErrIssuesDisabled,
+	migrate.ErrInsufficientScope,
+	migrate.ErrIsPullRequest,
+	migrate.ErrSkipLabel,
+	migrate.ErrSecurityIssue,
+	migrate.ErrUnassignable,
+	migrate.ErrInvalidLabel,
+	migrate.ErrInternalContent,
+	migrate.ErrBadExport,
+	ErrBadIssueNumber,
+	ErrBadDate,
+}
+
+// exitCode picks the process exit code for an error returned by a command
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	if errors.Is(err, migrate.ErrUserAborted) {
+		return exitAborted
+	}
+	if errors.Is(err, migrate.ErrAPIBudget) {
+		return exitBudget
+	}
+	var apiErr *migrate.APIError
+	if errors.As(err, &apiErr) {
+		return exitAPI
+	}
+	for _, v := range validationErrors {
+		if errors.Is(err, v) {
+			return exitValidation
+		}
+	}
+	return exitError
+}
+
+// errorMessage renders err for the terminal, adding a hint for common API failures
+func errorMessage(err error) string {
+	if errors.Is(err, migrate.ErrMissingLogin) {
+		return "--login must be set"
+	}
+	var budgetErr *migrate.APIBudgetError
+	if errors.As(err, &budgetErr) {
+		return fmt.Sprintf("%v\nRun again with --resume-from %d to continue.", err, budgetErr.Next)
+	}
+	var apiErr *migrate.APIError
+	if !errors.As(err, &apiErr) {
+		return err.Error()
+	}
+	if apiErr.InteractionLimited() {
+		return err.Error() + "\nInteraction limits are enabled on the repo or its org. Temporarily disable them, or run migratron with an account they exempt, such as a collaborator."
+	}
+	switch apiErr.StatusCode() {
+	case http.StatusUnauthorized:
+		return err.Error() + fmt.Sprintf("\nCheck that %s is set to a valid token.", tokenEnv)
+	case http.StatusForbidden:
+		return err.Error() + fmt.Sprintf("\nCheck that the token may write to %s and comment on %s.", toRepoEnv, fromRepoEnv)
+	case http.StatusNotFound:
+		return err.Error() + fmt.Sprintf("\nCheck %s/%s and that the token can access both repos.", fromRepoEnv, toRepoEnv)
+	}
+	return err.Error()
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+
+	"github.com/iancoffey/migratron/migrate"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	IssuesCmd.AddCommand(validateExportCmd)
+}
+
+var validateExportCmd = &cobra.Command{
+	Use:   "validate-export <file>",
+	Short: "check an export file against the export schema",
+	Args:  cobra.ExactArgs(1),
+	RunE:  validateExport,
+}
+
+func validateExport(cmd *cobra.Command, args []string) error {
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	issues, err := migrate.ReadExport(f)
+	if err != nil {
+		return err
+	}
+	cmd.Printf("%s: %d issues, schema version %d\n", args[0], len(issues), migrate.ExportSchemaVersion)
+	return nil
+}
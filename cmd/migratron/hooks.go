@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/iancoffey/migratron/migrate"
+	"github.com/spf13/cobra"
+)
+
+// Hook commands for downstream automation: perIssueHook runs after each
+// migrated issue and postHook once a run succeeded. Their failures are only
+// warnings unless hookStrict is set.
+var (
+	perIssueHook, postHook string
+	hookStrict             bool
+)
+
+// checkCommands rejects --transform-cmd, --per-issue-hook and --post-hook
+// values that hold nothing but space, before anything is migrated
+func checkCommands() error {
+	for _, c := range []struct{ flag, command string }{
+		{"transform-cmd", transformCmd},
+		{"per-issue-hook", perIssueHook},
+		{"post-hook", postHook},
+	} {
+		if c.command != "" && strings.TrimSpace(c.command) == "" {
+			return fmt.Errorf("%w: --%s is blank", migrate.ErrInvalidConfig, c.flag)
+		}
+	}
+	return nil
+}
+
+// runHook runs a hook command with args and extra env. Its output goes to
+// stderr so it does not mix with --output json.
+func runHook(command string, args []string, env ...string) error {
+	fields := strings.Fields(command)
+	cmd := exec.Command(fields[0], append(fields[1:], args...)...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// issueHook runs --per-issue-hook for a migrated issue, with the source and
+// target in MIGRATRON_HOOK_SOURCE(_URL) and MIGRATRON_HOOK_DEST(_URL)
+func issueHook(res migrate.Result) error {
+	if perIssueHook == "" {
+		return nil
+	}
+	err := runHook(perIssueHook, nil,
+		fmt.Sprintf("MIGRATRON_HOOK_SOURCE=%d", res.Source),
+		"MIGRATRON_HOOK_SOURCE_URL="+res.SourceURL,
+		fmt.Sprintf("MIGRATRON_HOOK_DEST=%d", res.Dest),
+		"MIGRATRON_HOOK_DEST_URL="+res.DestURL,
+	)
+	if err != nil {
+		return fmt.Errorf("per-issue hook for issue %d: %v", res.Source, err)
+	}
+	return nil
+}
+
+// runPostHook runs --post-hook once a run succeeded, passing the --report
+// path as its argument and in MIGRATRON_HOOK_REPORT
+func runPostHook(cmd *cobra.Command) error {
+	if postHook == "" {
+		return nil
+	}
+	var args []string
+	if reportPath != "" {
+		args = []string{reportPath}
+	}
+	if err := runHook(postHook, args, "MIGRATRON_HOOK_REPORT="+reportPath); err != nil {
+		return hookFailed(cmd, fmt.Errorf("post hook: %v", err))
+	}
+	return nil
+}
+
+// hookFailed returns err with --hook-strict, and otherwise only warns about it
+func hookFailed(cmd *cobra.Command, err error) error {
+	if hookStrict {
+		return err
+	}
+	cmd.Printf("Warning: %v\n", err)
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/iancoffey/migratron/migrate"
+	"github.com/spf13/cobra"
+)
+
+var labelsJSON bool
+
+func init() {
+	labelsDiffCmd.Flags().BoolVar(&labelsJSON, "json", false, "print the diff as JSON")
+	labelsDiffCmd.Flags().StringToStringVar(&labelMap, "label-map", nil, "rename a source label in the target, as src=dst (repeatable)")
+	labelsDiffCmd.Flags().StringSliceVar(&stripLabelPrefixes, "strip-label-prefix", nil, "remove this prefix from synced label names, e.g. internal/ (repeatable)")
+	labelsDiffCmd.Flags().StringToStringVar(&labelColors, "label-color-map", nil, "hex color for target labels by name, applied when they are created or reused, e.g. bug=d73a4a")
+	labelsDiffCmd.Flags().StringVar(&onInvalidLabel, "on-invalid-label", migrate.InvalidLabelSanitize, "when a synced label name is one GitHub rejects, like an empty or over 50 character one: skip drops it, fail aborts, sanitize trims and shortens it")
+	labelsDiffCmd.Flags().StringSliceVar(&addLabels, "add-label", nil, "labels added to every target issue, created if missing, e.g. imported-2024; repeatable")
+
+	RootCmd.AddCommand(LabelsCmd)
+	LabelsCmd.AddCommand(labelsDiffCmd)
+}
+
+var LabelsCmd = &cobra.Command{
+	Use:   "labels",
+	Short: "Tools to compare labels between repos",
+}
+
+var labelsDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "show which labels a migration would create in the target",
+	RunE:  labelsDiff,
+}
+
+func labelsDiff(cmd *cobra.Command, args []string) error {
+	cfg, err := repoConfig()
+	if err != nil {
+		return err
+	}
+	cfg.LabelMap = labelMap
+	cfg.StripLabelPrefixes = stripLabelPrefixes
+	cfg.AddLabels = addLabels
+	cfg.LabelColors = labelColors
+	cfg.OnInvalidLabel = onInvalidLabel
+
+	diff, err := migrate.New(cfg).DiffLabels(context.Background())
+	if err != nil {
+		return err
+	}
+	if labelsJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(diff)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Labels to create in %s:\n", cfg.To)
+	for _, l := range diff.Create {
+		fmt.Fprintf(out, "  + %s (#%s) %s\n", l.Name, l.Color, l.Description)
+	}
+	fmt.Fprintf(out, "Labels that differ from the source:\n")
+	for _, m := range diff.Mismatched {
+		fmt.Fprintf(out, "  ~ %s: color #%s -> #%s, description %q -> %q\n", m.Name, m.TargetColor, m.SourceColor, m.TargetDescription, m.SourceDescription)
+	}
+	fmt.Fprintf(out, "Labels only in the target:\n")
+	for _, name := range diff.TargetOnly {
+		fmt.Fprintf(out, "  - %s\n", name)
+	}
+	if len(diff.Invalid) > 0 {
+		action := "dropped"
+		if onInvalidLabel == migrate.InvalidLabelFail {
+			action = "failing the run"
+		}
+		fmt.Fprintf(out, "Labels GitHub rejects, %s by --on-invalid-label=%s:\n", action, onInvalidLabel)
+		for _, name := range diff.Invalid {
+			fmt.Fprintf(out, "  ! %q\n", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	logFilePath string
+	logMaxSize  int64
+	logKeep     int
+)
+
+func init() {
+	RootCmd.PersistentFlags().StringVar(&logFilePath, "log-file", "", "also write a JSON log of the run to this file")
+	RootCmd.PersistentFlags().Int64Var(&logMaxSize, "log-max-size", 10, "size in MB at which --log-file is rotated")
+	RootCmd.PersistentFlags().IntVar(&logKeep, "log-keep", 5, "number of rotated log files to keep")
+}
+
+// logEntry is one JSON line of the log file
+type logEntry struct {
+	Time   time.Time   `json:"time"`
+	Msg    string      `json:"msg"`
+	Result interface{} `json:"result,omitempty"`
+}
+
+// runLog writes JSON log lines to a file, rotating it to path.1, path.2, ...
+// once it grows past maxSize and keeping the last keep rotated files
+type runLog struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	keep    int
+	f       *os.File
+	size    int64
+	// partial holds progress text written without a trailing newline yet
+	partial []byte
+}
+
+func openRunLog(path string, maxSize int64, keep int) (*runLog, error) {
+	l := &runLog{path: path, maxSize: maxSize, keep: keep}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *runLog) open() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.f, l.size = f, fi.Size()
+	return nil
+}
+
+func (l *runLog) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return err
+	}
+	os.Remove(fmt.Sprintf("%s.%d", l.path, l.keep))
+	for i := l.keep - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+	}
+	if l.keep > 0 {
+		if err := os.Rename(l.path, l.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(l.path); err != nil {
+		return err
+	}
+	return l.open()
+}
+
+// log writes one entry, rotating the file first if it would grow too large
+func (l *runLog) log(e logEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if l.size > 0 && l.size+int64(len(b)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.f.Write(b)
+	l.size += int64(n)
+	return err
+}
+
+// Write logs each complete line of progress text as an entry, so the log can
+// be teed with the console output
+func (l *runLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.partial = append(l.partial, p...)
+	for {
+		i := bytes.IndexByte(l.partial, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := string(bytes.TrimSpace(l.partial[:i]))
+		l.partial = l.partial[i+1:]
+		if line == "" {
+			continue
+		}
+		if err := l.log(logEntry{Time: time.Now(), Msg: line}); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// logResult logs the result of one issue
+func (l *runLog) logResult(res interface{}) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.log(logEntry{Time: time.Now(), Msg: "result", Result: res})
+}
+
+func (l *runLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if line := string(bytes.TrimSpace(l.partial)); line != "" {
+		l.log(logEntry{Time: time.Now(), Msg: line})
+	}
+	return l.f.Close()
+}
@@ -2,19 +2,16 @@ package main
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
-	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/google/go-github/v36/github"
-	"github.com/manifoldco/promptui"
+	"github.com/iancoffey/migratron/migrate"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"golang.org/x/oauth2"
 )
 
 // Migratron - safely migrate a repo to another org
@@ -22,43 +19,148 @@ import (
 // making it simple to sync the labels, issues, comments to a new
 // project repo.
 
-const (
-	default_editor = "vim"
-)
-
 var (
-	issue                                       int
-	all                                         bool
 	migratedToLabel, migratedFromLabel, ghLogin string
-
-	badUriParts  = []string{"jira", "confluence.eng", "drive.google", "slack.com", "miro.com"}
-	bannedLabels = []string{"migration/essential"}
-	skipLabel    = "migration/selfservice"
+	includeClosed, onlyOpenInTarget             bool
+	preserveNumbers, includeResolution          bool
+	preserveOrder, includeDevRefs               bool
+	commentEditAll, includeParticipants         bool
+	interactive, assigneeStrict, createAsDraft  bool
+	incremental                                 bool
+	browsePrompt, combinedEdit, commentDedup    bool
+	allowSecurity, includeTypes, sourceComment  bool
+	failOnInternal, syncAssignees, useGraphQL   bool
+	includeSubIssues, assumeYes, noTarget       bool
+	syncMilestones, dryRun, preservePins        bool
+	includeDuplicates, sourceReadOnly           bool
+	confirmTarget, replicateReactions           bool
+	dryRunLabels, rewriteInternalLinks          bool
+	expandTeams, firstCommentAsBody             bool
+	codeFenceGuard, includeLabelHistory         bool
+	exportFile, targetMilestone, commentSince   string
+	stateFile, securityLabel, emojiMode         string
+	targetTemplate, onLabelError                string
+	assigneeFallback, onLabelCollision          string
+	markdownOut, searchQuery, confirmPhrase     string
+	overflowMode, dryRunOut                     string
+	commentAttribution, blocklistFile           string
+	assigneeStrictScope, onLargeComment         string
+	codeBlockPolicy, requireLabel               string
+	bodyPreambleFile, commentStyle              string
+	brokenAssetAction, onInvalidLabel           string
+	labelMap, typeMap, labelColors              map[string]string
+	urlMap, teamMap                             map[string]string
+	commentAuthors, stripLabelPrefixes          []string
+	pruneSourceLabels, requireAnyOf             []string
+	addLabels                                   []string
+	commentCollapseThreshold, maxBodyLength     int
+	minComments, minReactions, commentMaxSize   int
+	resumeFromIssue, labelHistoryLimit          int
+	maxAPICalls                                 int64
 )
 
-type issueSyncRequest struct {
-	number          int
-	syncAssignee    bool
-	syncLabels      bool
-	collateComments bool
-	body            string
-	title           string
-	fromRepo        string
-	toRepo          string
-}
-
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	migrateSingleIssueCmd.PersistentFlags().StringVar(&ghLogin, "login", "", "your github login")
-	migrateSingleIssueCmd.PersistentFlags().StringVar(&migratedToLabel, "to-label", "migration/migrated", "label to denote an issue has been processed and migrated")
-	migrateSingleIssueCmd.PersistentFlags().StringVar(&migratedToLabel, "from-label", "migration/imported", "label to denote an issue has been created as result of an import")
-	migrateSingleIssueCmd.PersistentFlags().StringVar(&migratedToLabel, "from-label", "migration/imported", "label to denote an issue has been created as result of an import")
-
-	migrateAllIssueCmd.PersistentFlags().StringVar(&ghLogin, "login", "", "your github login")
-	migrateAllIssueCmd.PersistentFlags().StringVar(&migratedToLabel, "to-label", "migration/migrated", "label to denote an issue has been processed and migrated")
-	migrateAllIssueCmd.PersistentFlags().StringVar(&migratedFromLabel, "from-label", "migration/imported", "label to denote an issue has been created as result of an import")
-	migrateAllIssueCmd.PersistentFlags().StringVar(&migratedFromLabel, "from-label", "migration/imported", "label to denote an issue has been created as result of an import")
+	// flags preparing source content, which drift --apply prepares the same way
+	for _, c := range []*cobra.Command{migrateSingleIssueCmd, migrateAllIssueCmd, batchCmd, driftIssuesCmd} {
+		c.PersistentFlags().StringVar(&transformCmd, "transform-cmd", "", "command every body and comment is piped through before migration, see the README")
+		c.PersistentFlags().StringToStringVar(&urlMap, "url-map", nil, "rewrite links to an internal host to a public one in bodies and comments, as internalhost=publichost; an empty publichost removes the link (repeatable)")
+		c.PersistentFlags().BoolVar(&rewriteInternalLinks, "comment-link-rewrite", false, "replace links holding internal terms in bodies and comments with a placeholder")
+		c.PersistentFlags().StringVar(&brokenAssetAction, "broken-asset-action", migrate.AssetActionWarn, "what happens to images and attachments of a private source that public viewers can not load: warn, or placeholder to replace them")
+		c.PersistentFlags().StringVar(&emojiMode, "emoji", migrate.EmojiKeep, "emoji shortcodes in migrated text, keep or strip")
+	}
+
+	for _, c := range []*cobra.Command{migrateSingleIssueCmd, migrateAllIssueCmd, batchCmd} {
+		c.PersistentFlags().StringVar(&ghLogin, "login", "", "your github login")
+		c.PersistentFlags().StringVar(&migratedToLabel, "to-label", migrate.DefaultMigratedToLabel, "label to denote an issue has been processed and migrated")
+		c.PersistentFlags().StringVar(&migratedFromLabel, "from-label", migrate.DefaultMigratedFromLabel, "label to denote an issue has been created as result of an import")
+		c.PersistentFlags().BoolVar(&browsePrompt, "browse", false, "offer to open each source issue in the browser before deciding to import it")
+		c.PersistentFlags().BoolVar(&commentDedup, "comment-dedup", false, "drop comments whose body duplicates an earlier comment when collating")
+		c.PersistentFlags().BoolVar(&firstCommentAsBody, "first-comment-as-body", false, "use the first comment, with attribution, as the body of issues whose body is empty or trivially short, instead of collating it")
+		c.PersistentFlags().BoolVar(&combinedEdit, "combined-edit", false, "edit the title, body and collated comments in a single editor session")
+		c.PersistentFlags().BoolVar(&sourceComment, "target-source-comment", false, "comment on each target issue with a link back to its source issue")
+		c.PersistentFlags().BoolVar(&includeResolution, "include-resolution", false, "note the pull requests that resolved a closed source issue in the migrated body")
+		c.PersistentFlags().StringVar(&commentSince, "comment-since", "", "only collate comments created on or after this date (YYYY-MM-DD or RFC3339)")
+		c.PersistentFlags().BoolVar(&includeTypes, "include-types", false, "set the source issue type on the target issue, or note it in the body if the target has no such type")
+		c.PersistentFlags().StringToStringVar(&typeMap, "type-map", nil, "rename a source issue type in the target, as src=dst (repeatable)")
+		c.PersistentFlags().StringVar(&onInvalidLabel, "on-invalid-label", migrate.InvalidLabelSanitize, "when a synced label name is one GitHub rejects, like an empty or over 50 character one: skip drops it, fail aborts, sanitize trims and shortens it")
+		c.PersistentFlags().StringVar(&onLabelError, "on-label-error", migrate.LabelErrorWarn, "when a synced label can not be created in the target: skip drops it, warn drops it with a warning, fail aborts")
+		c.PersistentFlags().StringVar(&onLabelCollision, "label-on-collision", migrate.LabelCollisionReuse, "when a synced label differs from the target label of the same name: reuse, rename (adds a -migrated suffix) or update")
+		c.PersistentFlags().StringSliceVar(&commentAuthors, "comment-author-allow", nil, "only collate comments by this login (repeatable), bots are dropped unless listed")
+		c.PersistentFlags().StringSliceVar(&pruneSourceLabels, "prune-source-labels", nil, "label removed from the source issue once it is migrated (repeatable)")
+		c.PersistentFlags().StringSliceVar(&addLabels, "add-label", nil, "labels added to every target issue, created if missing, e.g. imported-2024; repeatable")
+		c.PersistentFlags().StringVar(&requireLabel, "require-label", "", "label every target issue must carry, added when the synced labels lack it")
+		c.PersistentFlags().StringSliceVar(&requireAnyOf, "require-any-of", nil, "fail issues whose target labels include none of these, e.g. bug,feature,question")
+		c.PersistentFlags().StringSliceVar(&stripLabelPrefixes, "strip-label-prefix", nil, "remove this prefix from synced label names, e.g. internal/ (repeatable)")
+		c.PersistentFlags().IntVar(&commentCollapseThreshold, "comment-collapse-threshold", 0, "collapse the collated comments of issues with more comments than this into an expandable block, 0 never collapses")
+		c.PersistentFlags().BoolVar(&includeDevRefs, "include-dev-refs", false, "note the branches and pull requests linked to each issue's Development section in the migrated body")
+		c.PersistentFlags().BoolVar(&includeLabelHistory, "include-label-history", false, "note when labels were added to or removed from each issue, and by whom, in the migrated body")
+		c.PersistentFlags().IntVar(&labelHistoryLimit, "label-history-limit", migrate.DefaultLabelHistoryLimit, "most recent label events --include-label-history notes")
+		c.PersistentFlags().BoolVar(&includeParticipants, "include-participants", false, "note the reporter, assignees and commenters of each issue in the migrated body, without pinging them")
+		c.PersistentFlags().BoolVar(&confirmTarget, "confirm-target", false, "require typing the target org/repo before migrating all issues, even with --yes")
+		c.PersistentFlags().BoolVar(&sourceReadOnly, "source-readonly", false, "never write to the source repo: no backlink comment, migrated label or label pruning")
+		c.PersistentFlags().BoolVar(&createAsDraft, "create-as-draft", false, "create target issues closed and labelled "+migrate.DefaultPendingReviewLabel+", for a reviewer to reopen once verified")
+		c.PersistentFlags().BoolVar(&replicateReactions, "replicate-reactions", false, "add one reaction of each kind the source issue received to the target issue; they are attributed to the token's user")
+		c.PersistentFlags().BoolVar(&preservePins, "preserve-pins", false, "pin the target issues migrated from pinned source issues, up to GitHub's limit of 3")
+		c.PersistentFlags().BoolVar(&interactive, "interactive", true, "review each issue; with --interactive=false clean issues are migrated unattended and the rest listed for manual review")
+		c.PersistentFlags().BoolVar(&commentEditAll, "comment-edit-all", false, "open every collated comment in the editor before it is added")
+		c.PersistentFlags().StringVar(&perIssueHook, "per-issue-hook", "", "command run after each migrated issue, with MIGRATRON_HOOK_SOURCE, MIGRATRON_HOOK_SOURCE_URL, MIGRATRON_HOOK_DEST and MIGRATRON_HOOK_DEST_URL set")
+		c.PersistentFlags().StringVar(&postHook, "post-hook", "", "command run once a run succeeded, with the --report path as its argument and in MIGRATRON_HOOK_REPORT")
+		c.PersistentFlags().BoolVar(&hookStrict, "hook-strict", false, "fail the run when a hook exits non-zero instead of warning")
+		c.PersistentFlags().StringVar(&blocklistFile, "blocklist-file", "", "file of extra internal terms, one per line, # for comments and re: for regular expressions")
+		c.PersistentFlags().IntVar(&commentMaxSize, "comment-max-size", 0, "most characters a collated comment may have before --large-comment applies, 0 for no limit")
+		c.PersistentFlags().StringVar(&onLargeComment, "large-comment", migrate.LargeCommentCollapse, "what happens to comments over --comment-max-size: collapse into a <details> block, or truncate with a link to the source")
+		c.PersistentFlags().StringVar(&bodyPreambleFile, "body-preamble-file", "", "file holding a Go template prepended to every migrated body, with {{.Number}}, {{.Title}}, {{.URL}}, {{.Author}}, {{.CreatedAt}}, {{.State}} and {{.Repo}}")
+		c.PersistentFlags().StringVar(&commentAttribution, "comment-attribution-format", migrate.DefaultCommentAttribution, "Go template rendering each collated comment, with {{.Author}}, {{.CreatedAt}}, {{.URL}} and {{.Body}}; overrides --comment-style")
+		c.PersistentFlags().StringVar(&commentStyle, "comment-style", migrate.CommentStyleBlock, "how collated comments are attributed: block, a header with date and author, or inline, the author in bold before the body")
+		c.PersistentFlags().IntVar(&maxBodyLength, "comment-max-length", migrate.DefaultMaxBodyLength, "most characters a migrated body may have, including collated comments")
+		c.PersistentFlags().StringVar(&overflowMode, "overflow", migrate.OverflowTruncate, "what happens to collated comments past --comment-max-length: truncate, or comments to post the rest as follow-up comments")
+		c.PersistentFlags().StringToStringVar(&labelColors, "label-color-map", nil, "hex color for target labels by name, applied when they are created or reused, e.g. bug=d73a4a")
+		c.PersistentFlags().StringToStringVar(&labelMap, "label-map", nil, "rename a source label in the target, as src=dst (repeatable)")
+		c.PersistentFlags().StringVar(&markdownOut, "markdown-out", "", "directory to write a markdown file per migrated issue to")
+		c.PersistentFlags().BoolVar(&noTarget, "no-target", false, "only write issues to --markdown-out, without creating them in the target")
+		c.PersistentFlags().StringVar(&outputFormat, "output", "text", "per-issue result format, text or json")
+		c.PersistentFlags().StringVar(&reportPath, "report", "", "append a JSON line per issue result to this file")
+		c.PersistentFlags().StringVar(&securityLabel, "security-label", migrate.DefaultSecurityLabel, "label marking issues with security sensitive details, which are refused")
+		c.PersistentFlags().StringVar(&targetTemplate, "target-template", "", "issue template or form of the target whose structure is prepended to every migrated body")
+		c.PersistentFlags().StringVar(&codeBlockPolicy, "code-block-policy", migrate.CodeBlockScan, "how internal terms in fenced code blocks are matched: scan like prose, strict to also match regardless of case, or ignore")
+		c.PersistentFlags().BoolVar(&codeFenceGuard, "comment-code-fence-guard", false, "ask to edit again when an edit leaves a code block open")
+		c.PersistentFlags().StringVar(&confirmPhrase, "confirm-phrase", "", "phrase to type instead of y to migrate an issue with internal terms, {number} is replaced by the issue number")
+		c.PersistentFlags().BoolVar(&failOnInternal, "fail-on-internal", false, "scan every issue for internal terms first and abort before creating anything if any are found")
+		c.PersistentFlags().BoolVar(&allowSecurity, "allow-security", false, "migrate issues with the security label or security advisory links instead of refusing them")
+		c.PersistentFlags().BoolVar(&syncAssignees, "sync-assignees", false, "carry over the source assignees that can be assigned in the target")
+		c.PersistentFlags().BoolVar(&assigneeStrict, "assignee-sync-strict", false, "with --sync-assignees, refuse to drop assignees that can not be assigned in the target")
+		c.PersistentFlags().StringVar(&assigneeStrictScope, "assignee-strict-scope", "issue", "what --assignee-sync-strict refuses: issue to skip the issue, or run to stop the run")
+		c.PersistentFlags().BoolVar(&expandTeams, "expand-teams", false, "assign the members --team-map lists for each team mentioned in an issue body, noting those that can not be assigned")
+		c.PersistentFlags().StringToStringVar(&teamMap, "team-map", nil, "members of a source team for --expand-teams, as org/team=user1,user2 (repeatable)")
+		c.PersistentFlags().StringVar(&assigneeFallback, "assignee-fallback", "", "login assigned to migrated issues left without an assignee, must be a target collaborator")
+		c.PersistentFlags().BoolVar(&syncMilestones, "sync-milestones", false, "assign each issue to the target milestone titled like its source milestone, created with the same state, due date and description")
+		c.PersistentFlags().BoolVar(&syncMilestones, "milestone-on-closed", false, "alias of --sync-milestones, which reuses closed milestones and creates them closed")
+		c.PersistentFlags().StringVar(&targetMilestone, "target-milestone", "", "milestone title every migrated issue is assigned to, created in the target if missing")
+	}
+
+	migrateAllIssueCmd.PersistentFlags().BoolVar(&includeClosed, "include-closed", false, "also migrate closed issues")
+	batchCmd.PersistentFlags().BoolVar(&useGraphQL, "use-graphql", false, "fetch issue comments in bulk over GraphQL, falling back to REST on errors")
+	batchCmd.PersistentFlags().BoolVar(&includeClosed, "include-closed", false, "also migrate closed issues, unless a manifest entry sets include_closed")
+	migrateAllIssueCmd.PersistentFlags().BoolVar(&onlyOpenInTarget, "only-open-in-target", false, "with --include-closed, write closed issues to --export-file instead of creating them in the target")
+	migrateAllIssueCmd.PersistentFlags().BoolVar(&preserveNumbers, "preserve-numbers", false, "create closed placeholder issues in an empty target so migrated issues keep their source numbers")
+	migrateAllIssueCmd.PersistentFlags().IntVar(&minComments, "min-comments", 0, "skip issues with fewer comments")
+	migrateAllIssueCmd.PersistentFlags().IntVar(&minReactions, "min-reactions", 0, "skip issues with fewer reactions")
+	migrateAllIssueCmd.PersistentFlags().BoolVar(&preserveOrder, "preserve-order", false, "migrate issues oldest first so target numbers follow the source chronology")
+	migrateAllIssueCmd.PersistentFlags().IntVar(&resumeFromIssue, "resume-from", 0, "skip the issues processed before this source issue number, to resume a run that stopped part way")
+	migrateAllIssueCmd.PersistentFlags().Int64Var(&maxAPICalls, "max-api-calls", 0, "stop before the next issue once this many API calls were made, to resume later with --resume-from; 0 for no limit")
+	migrateAllIssueCmd.PersistentFlags().BoolVar(&incremental, "incremental", false, "only consider issues created since the last successful run recorded in --state-file")
+	migrateAllIssueCmd.PersistentFlags().StringVar(&stateFile, "state-file", ".migratron-state.json", "file recording the time of the last successful run")
+	migrateAllIssueCmd.PersistentFlags().StringVar(&searchQuery, "query", "", "only migrate the issues matching this GitHub search query, scoped to the source repo")
+	migrateAllIssueCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "write the planned outcome of every issue as sorted JSON lines without prompting or changing anything")
+	migrateAllIssueCmd.PersistentFlags().BoolVar(&dryRunLabels, "dry-run-create-labels", false, "like --dry-run, but creates the labels the planned issues would sync in the target; no issues or comments are created")
+	migrateAllIssueCmd.PersistentFlags().StringVar(&dryRunOut, "dry-run-out", "-", "file --dry-run writes its plan to, - for stdout")
+	migrateAllIssueCmd.PersistentFlags().BoolVar(&assumeYes, "yes", false, "start without confirming the run summary")
+	migrateAllIssueCmd.PersistentFlags().BoolVar(&includeDuplicates, "include-duplicates", false, "note which issue each migrated issue was marked a duplicate of, pointing at its migrated copy when there is one")
+	migrateAllIssueCmd.PersistentFlags().BoolVar(&includeSubIssues, "include-subissues", false, "re-establish parent/sub-issue links between migrated issues, noting links to unmigrated issues in the body")
+	migrateAllIssueCmd.PersistentFlags().BoolVar(&useGraphQL, "use-graphql", false, "fetch issue comments in bulk over GraphQL, falling back to REST on errors")
+	migrateAllIssueCmd.PersistentFlags().StringVar(&exportFile, "export-file", "migratron-export.json", "file closed issues are written to when --only-open-in-target is set")
 
 	RootCmd.AddCommand(IssuesCmd)
 	IssuesCmd.AddCommand(migrateSingleIssueCmd)
@@ -66,13 +168,18 @@ func init() {
 }
 
 func main() {
-	RootCmd.Execute()
+	if err := RootCmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", errorMessage(err))
+		os.Exit(exitCode(err))
+	}
 }
 
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
-	Use:   "migratron",
-	Short: "Tools for migrating repositories",
+	Use:           "migratron",
+	Short:         "Tools for migrating repositories",
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 var IssuesCmd = &cobra.Command{
@@ -92,384 +199,340 @@ var migrateAllIssueCmd = &cobra.Command{
 	RunE:  migrateAllIssue,
 }
 
-// Migrate issues as a transaction to avoid any inconsistencies from manual copying
-func migrateAllIssue(cmd *cobra.Command, args []string) error {
-	if ghLogin == "" {
-		return errors.New("--login must be set!")
-	}
-
-	ctx := context.Background()
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: viper.GetString("TOKEN")},
-	)
-	tc := oauth2.NewClient(ctx, ts)
-	client := github.NewClient(tc)
-
-	repoParts := strings.Split(viper.GetString("FROM_REPO"), "/")
-	if len(repoParts) < 2 || len(repoParts) > 2 {
-		return fmt.Errorf("FROM_REPO env is not in org/repo format: %q", viper.GetString("FROM_REPO"))
-	}
-	toRepoParts := strings.Split(viper.GetString("TO_REPO"), "/")
-	if len(repoParts) < 2 || len(repoParts) > 2 {
-		return fmt.Errorf("TO_REPO env is not in org/repo format: %q", viper.GetString("TO_REPO"))
-	}
-	fromRepo := ghRepo{
-		org:  repoParts[0],
-		name: repoParts[1],
-	}
-	toRepo := ghRepo{
-		org:  toRepoParts[0],
-		name: toRepoParts[1],
-	}
-
-	issues, _, err := client.Issues.ListByRepo(ctx,
-		repoParts[0],
-		repoParts[1],
-		&github.IssueListByRepoOptions{
-			ListOptions: github.ListOptions{
-				PerPage: 1000,
-			},
-			Sort:      "created",
-			Direction: "desc",
-		})
+// repoConfig builds a migrate.Config holding the token and repos from the
+// environment, asking for the repos that are not set when run interactively
+func repoConfig() (migrate.Config, error) {
+	cfg, err := clientConfig()
 	if err != nil {
-		return err
+		return cfg, err
 	}
-OUTER:
-	for _, i := range issues {
-		if i.IsPullRequest() {
-			continue
-		}
-
-		for _, l := range i.Labels {
-			if *l.Name == skipLabel || *l.Name == migratedToLabel {
-				cmd.Printf("skipped: %d\n", *i.Number)
-				continue OUTER
-			}
-		}
-		if err := migrateOne(ctx, cmd, i, client, toRepo, fromRepo); err != nil {
-			return err
-		}
+	if cfg.From, err = configuredRepo(cfg, "FROM_REPO", fromRepoEnv, "Source repo", true); err != nil {
+		return migrate.Config{}, err
 	}
-
-	cmd.Println("Completed all issues!")
-
-	return nil
+	if cfg.To, err = configuredRepo(cfg, "TO_REPO", toRepoEnv, "Target repo", false); err != nil {
+		return migrate.Config{}, err
+	}
+	return cfg, nil
 }
 
-type ghRepo struct {
-	org  string
-	name string
+// migrateConfig builds the migrate.Config for the issue migration commands from flags and env
+func migrateConfig(cmd *cobra.Command, rep *reporter) (migrate.Config, error) {
+	cfg, err := repoConfig()
+	if err != nil {
+		return cfg, err
+	}
+	return withMigrateFlags(cmd, rep, cfg)
 }
 
-// Migrate issues as a transaction to avoid any inconsistencies from manual copying
-func migrateSingleIssue(cmd *cobra.Command, args []string) error {
-	if ghLogin == "" {
-		return errors.New("--login must be set!")
+// withMigrateFlags applies the issue migration flags to cfg
+func withMigrateFlags(cmd *cobra.Command, rep *reporter, cfg migrate.Config) (migrate.Config, error) {
+	if err := checkCommands(); err != nil {
+		return cfg, err
 	}
-
-	repoParts := strings.Split(viper.GetString("FROM_REPO"), "/")
-	if len(repoParts) < 2 || len(repoParts) > 2 {
-		return fmt.Errorf("FROM_REPO env is not in org/repo format: %q", viper.GetString("FROM_REPO"))
+	since, err := parseDate(commentSince)
+	if err != nil {
+		return cfg, err
+	}
+
+	cfg.Login = ghLogin
+	cfg = withContentFlags(cfg)
+	cfg.MigratedToLabel = migratedToLabel
+	cfg.MigratedFromLabel = migratedFromLabel
+	cfg.PruneSourceLabels = pruneSourceLabels
+	cfg.LabelMap = labelMap
+	cfg.LabelColors = labelColors
+	cfg.StripLabelPrefixes = stripLabelPrefixes
+	cfg.AddLabels = addLabels
+	cfg.RequireLabel = requireLabel
+	cfg.RequireAnyOf = requireAnyOf
+	cfg.OnLabelError = onLabelError
+	cfg.OnInvalidLabel = onInvalidLabel
+	cfg.OnLabelCollision = onLabelCollision
+	cfg.IncludeTypes = includeTypes
+	cfg.TypeMap = typeMap
+	cfg.TargetMilestone = targetMilestone
+	cfg.TargetTemplate = targetTemplate
+	cfg.SyncMilestones = syncMilestones
+	cfg.SyncAssignees = syncAssignees
+	cfg.AssigneeFallback = assigneeFallback
+	cfg.ExpandTeams = expandTeams
+	if len(teamMap) > 0 {
+		cfg.TeamMap = map[string][]string{}
+		for team, members := range teamMap {
+			for _, login := range strings.Split(members, ",") {
+				if login = strings.TrimSpace(login); login != "" {
+					cfg.TeamMap[team] = append(cfg.TeamMap[team], login)
+				}
+			}
+		}
 	}
-	toRepoParts := strings.Split(viper.GetString("TO_REPO"), "/")
-	if len(repoParts) < 2 || len(repoParts) > 2 {
-		return fmt.Errorf("TO_REPO env is not in org/repo format: %q", viper.GetString("TO_REPO"))
+	if assigneeStrict {
+		switch assigneeStrictScope {
+		case "issue":
+			cfg.OnAssigneeError = migrate.AssigneeErrorSkip
+		case "run":
+			cfg.OnAssigneeError = migrate.AssigneeErrorFail
+		default:
+			return cfg, fmt.Errorf("%w: --assignee-strict-scope must be issue or run, got %q", migrate.ErrInvalidConfig, assigneeStrictScope)
+		}
 	}
+	cfg.IncludeResolution = includeResolution
+	cfg.SourceComment = sourceComment
+	cfg.CommentSince = since
+	cfg.CommentAuthors = commentAuthors
+	cfg.CommentDedup = commentDedup
+	cfg.FirstCommentAsBody = firstCommentAsBody
+	cfg.CommentCollapseThreshold = commentCollapseThreshold
+	cfg.CommentStyle = commentStyle
+	if cmd.Flags().Changed("comment-attribution-format") {
+		cfg.CommentAttribution = commentAttribution
+	}
+	if bodyPreambleFile != "" {
+		preamble, err := os.ReadFile(bodyPreambleFile)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.BodyPreamble = string(preamble)
+	}
+	cfg.CommentMaxSize = commentMaxSize
+	cfg.OnLargeComment = onLargeComment
+	if cfg, err = withBlocklistFile(cfg); err != nil {
+		return cfg, err
+	}
+	cfg.CommentEditAll = commentEditAll
+	cfg.AutoClean = !interactive
+	cfg.PreservePins = preservePins
+	cfg.ReplicateReactions = replicateReactions
+	cfg.CreateAsDraft = createAsDraft
+	cfg.SourceReadOnly = sourceReadOnly
+	cfg.ConfirmTarget = confirmTarget
+	cfg.IncludeDevRefs = includeDevRefs
+	cfg.IncludeParticipants = includeParticipants
+	cfg.IncludeLabelHistory = includeLabelHistory
+	cfg.LabelHistoryLimit = labelHistoryLimit
+	cfg.MaxBodyLength = maxBodyLength
+	cfg.Overflow = overflowMode
+	cfg.CombinedEdit = combinedEdit
+	cfg.Browse = browsePrompt
+	cfg.UseGraphQL = useGraphQL
+	cfg.MarkdownDir = markdownOut
+	cfg.NoTarget = noTarget
+	cfg.IncludeSubIssues = includeSubIssues
+	cfg.IncludeDuplicates = includeDuplicates
+	cfg.SecurityLabel = securityLabel
+	cfg.AllowSecurity = allowSecurity
+	cfg.FailOnInternal = failOnInternal
+	cfg.ConfirmPhrase = confirmPhrase
+	cfg.CodeBlockPolicy = codeBlockPolicy
+	cfg.CodeFenceGuard = codeFenceGuard
+	cfg.Prompter = newTerminalPrompter()
+	cfg.Out = rep.output()
+	// progress is teed to the log file, which should not hold escape codes
+	cfg.Color = colorEnabled() && logFilePath == ""
+	cfg.OnEvent = rep.event
+	return cfg, nil
+}
 
-	fromRepo := ghRepo{
-		org:  repoParts[0],
-		name: repoParts[1],
-	}
-	toRepo := ghRepo{
-		org:  toRepoParts[0],
-		name: toRepoParts[1],
+// Migrate issues as a transaction to avoid any inconsistencies from manual copying
+func migrateAllIssue(cmd *cobra.Command, args []string) error {
+	if onlyOpenInTarget && !includeClosed {
+		return fmt.Errorf("%w: --only-open-in-target requires --include-closed", migrate.ErrInvalidConfig)
 	}
 
-	ctx := context.Background()
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: viper.GetString("TOKEN")},
-	)
-	tc := oauth2.NewClient(ctx, ts)
-	client := github.NewClient(tc)
-	if len(args) == 0 {
-		return errors.New("No issue number provided")
-	}
-	issue, err := strconv.Atoi(args[0])
+	rep, err := newReporter(cmd)
 	if err != nil {
 		return err
 	}
-
-	ghIssue, _, err := client.Issues.Get(ctx, fromRepo.org, fromRepo.name, issue)
+	cfg, err := migrateConfig(cmd, rep)
 	if err != nil {
 		return err
 	}
-	if ghIssue.IsPullRequest() {
-		return errors.New("This is a PR, can not migrate")
-	}
+	cfg.MaxAPICalls = maxAPICalls
 
-	for _, l := range ghIssue.Labels {
-		if *l.Name == skipLabel {
-			return errors.New("This issue has label migration/selfservice applied, exiting")
-		}
+	opts := migrate.AllOptions{
+		IncludeClosed:   includeClosed,
+		PreserveNumbers: preserveNumbers,
+		PreserveOrder:   preserveOrder,
+		MinComments:     minComments,
+		MinReactions:    minReactions,
+		ResumeFrom:      resumeFromIssue,
+		Yes:             assumeYes,
+		Query:           searchQuery,
 	}
-	if err := migrateOne(ctx, cmd, ghIssue, client, toRepo, fromRepo); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func migrateOne(ctx context.Context, cmd *cobra.Command, issue *github.Issue, client *github.Client, to, from ghRepo) error {
-	c, _, err := client.Issues.ListComments(ctx, from.org, from.name, *issue.Number, &github.IssueListCommentsOptions{})
-	if err != nil {
-		return err
+	if dryRun || dryRunLabels {
+		opts.PlanLabels = dryRunLabels
+		return planAll(cmd, rep, cfg, opts)
 	}
-	cmd.Println("-------------------------------")
-	cmd.Printf("Migrating Issue %d\nTitle: %q\nBody: %q\nURL: %s\n\n", *issue.Number, *issue.Title, *issue.Body, *issue.HTMLURL)
-	// Import?
-	importPrompt := promptui.Prompt{
-		Label:     "Import Issue?",
-		IsConfirm: true,
-	}
-	importIssue, _ := importPrompt.Run()
-	if importIssue != "y" {
-		return nil
-	}
-
-	req, err := generateIssueRequest(cmd, issue, c)
-	if err != nil {
-		return err
+	if onlyOpenInTarget {
+		export, err := os.OpenFile(exportFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return err
+		}
+		defer export.Close()
+		opts.Export = export
 	}
 
-	migrationPrompt := promptui.Prompt{
-		Label:     "Migrate Resource?",
-		IsConfirm: true,
-	}
-	m, err := migrationPrompt.Run()
-	if err != nil {
-		return err
-	}
-	if m != "y" {
-		return nil
+	var state *runState
+	started := time.Now()
+	if incremental {
+		state, err = loadState(stateFile)
+		if err != nil {
+			return err
+		}
+		opts.Since = state.LastMigration[stateKey(cfg.From, cfg.To)]
+		if !opts.Since.IsZero() {
+			cmd.Printf("Considering issues created since %s\n", opts.Since.Format(time.RFC3339))
+		}
 	}
 
-	newIssue, _, err := client.Issues.Create(ctx, to.org, to.name, req)
-	if err != nil {
-		return err
+	m := migrate.New(cfg)
+	_, err = m.MigrateAll(context.Background(), opts)
+	rep.printStats(m.Stats(), time.Since(started))
+	if closeErr := rep.Close(); err == nil {
+		err = closeErr
 	}
-	finalIssue, _, err := client.Issues.Get(ctx, to.org, to.name, *newIssue.Number)
 	if err != nil {
 		return err
 	}
 
-	myUser, _, err := client.Users.Get(ctx, ghLogin)
-	if err != nil {
-		return err
-	}
-	commentBody := "Migrated to " + *finalIssue.HTMLURL + "."
-	comment := github.IssueComment{
-		Body: &commentBody,
-		User: myUser,
-	}
-	_, _, err = client.Issues.CreateComment(ctx, from.org, from.name, *issue.Number, &comment)
-	if err != nil {
-		return err
+	if state != nil {
+		state.LastMigration[stateKey(cfg.From, cfg.To)] = started
+		if err := state.save(stateFile); err != nil {
+			return err
+		}
 	}
 
-	_, _, err = client.Issues.AddLabelsToIssue(ctx, from.org, from.name, *issue.Number, []string{migratedToLabel})
-	if err != nil {
+	if err := runPostHook(cmd); err != nil {
 		return err
 	}
-
-	cmd.Print("\n-------------------------------\n")
-	cmd.Printf("Successfully migrated issue %d to:\n", issue.Number)
-	cmd.Println(*finalIssue.HTMLURL)
-	cmd.Printf("Please review each issue for accuracy")
-	cmd.Print("\n-------------------------------\n\n")
+	cmd.Println("Completed all issues!")
 
 	return nil
 }
 
-func scanForInternal(s *string) bool {
-	for _, b := range badUriParts {
-		if strings.Contains(*s, b) {
-			return true
-		}
-	}
-	return false
-}
-
-func generateIssueRequest(cmd *cobra.Command, issue *github.Issue, comments []*github.IssueComment) (*github.IssueRequest, error) {
-	req := &github.IssueRequest{
-		Title: issue.Title,
-		Body:  issue.Body,
+// planAll writes the plan of a migrate all run to --dry-run-out
+func planAll(cmd *cobra.Command, rep *reporter, cfg migrate.Config, opts migrate.AllOptions) error {
+	if onlyOpenInTarget {
+		opts.Export = ioutil.Discard
 	}
-
-	// Edit the title
-	editTitlePrompt := promptui.Prompt{
-		Label:     "Edit Title",
-		IsConfirm: true,
-	}
-	if scanForInternal(issue.Title) {
-		editTitlePrompt.Label = "Issue Title Alert! Internal Terms found in title. Please be sure to edit!"
-	}
-	editTitle, _ := editTitlePrompt.Run()
-	if editTitle == "y" {
-		updateTitlePrompt := promptui.Prompt{
-			Label:     "Update Title",
-			Default:   *issue.Title,
-			AllowEdit: true,
-		}
-		u, err := updateTitlePrompt.Run()
+	if incremental {
+		state, err := loadState(stateFile)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		req.Title = &u
+		opts.Since = state.LastMigration[stateKey(cfg.From, cfg.To)]
 	}
 
-	// Edit the body
-	editBodyPrompt := promptui.Prompt{
-		Label:     "Edit Body",
-		IsConfirm: true,
-	}
-	if scanForInternal(issue.Body) {
-		editBodyPrompt.Label = "Issue Body Alert! Internal Terms found in body. Please be sure to edit!"
-	}
-	editBody, _ := editBodyPrompt.Run()
-	if editBody == "y" {
-		bodyBytes, err := editBodyVim("migratron.*.body.txt", *issue.Body)
+	out := cmd.OutOrStdout()
+	if dryRunOut != "-" {
+		f, err := os.Create(dryRunOut)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		bodyString := string(bodyBytes)
-		req.Body = &bodyString
+		defer f.Close()
+		out = f
 	}
+	opts.Plan = out
 
-	// Sync labels
-	syncLabelPrompt := promptui.Prompt{
-		Label:     "Sync Labels",
-		IsConfirm: true,
-	}
-	syncLabels, _ := syncLabelPrompt.Run()
-	if syncLabels == "y" {
-		synced := assertAndSyncLabels(issue.Labels)
-		req.Labels = &synced
+	_, err := migrate.New(cfg).MigrateAll(context.Background(), opts)
+	if closeErr := rep.Close(); err == nil {
+		err = closeErr
 	}
+	return err
+}
 
-	// Sync labels
-	collateCommentsPrompt := promptui.Prompt{
-		Label:     "Collate Comments",
-		IsConfirm: true,
+// Migrate issues as a transaction to avoid any inconsistencies from manual copying
+func migrateSingleIssue(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: no issue number provided", ErrBadIssueNumber)
 	}
-	collate, _ := collateCommentsPrompt.Run()
-	if collate == "y" {
-		collated, err := collateComments(cmd, comments)
-		if err != nil {
-			return nil, err
-		}
-		if len(collated) > 0 {
-			updatedBody := *req.Body + "\n### Collated Context\n" + string(collated)
-			req.Body = &updatedBody
-		}
+	issue, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrBadIssueNumber, args[0])
 	}
 
-	return req, nil
-}
-
-func assertAndSyncLabels(labels []*github.Label) []string {
-	toLabels := []string{migratedFromLabel}
-	for _, l := range labels {
-		for _, banned := range bannedLabels {
-			if *l.Name == banned {
-				continue
-			}
-		}
-		toLabels = append(toLabels, *l.Name)
+	rep, err := newReporter(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := migrateConfig(cmd, rep)
+	if err != nil {
+		return err
 	}
-	return toLabels
-}
-
-// collateComments
-func collateComments(cmd *cobra.Command, comments []*github.IssueComment) (cBytes []byte, err error) {
-	var collated, addComment string
-	for _, comment := range comments {
-		if scanForInternal(comment.Body) {
-			cmd.Printf("\nAlert! Internal Terms found in comment. Forcing edit!")
-		}
-
-		cmd.Printf("\nComment: %s\n", *comment.Body)
-		addCommentPrompt := promptui.Prompt{
-			Label:     "Add Comment",
-			IsConfirm: true,
-		}
-		if scanForInternal(comment.Body) {
-			addCommentPrompt.Label = "Comment Alert! Internal Terms found in comment. Please be sure to edit!"
-		}
-		addComment, _ = addCommentPrompt.Run()
-		if addComment != "y" {
-			continue
-		}
 
-		commentMetadata := fmt.Sprintf("\nContext from %s", comment.CreatedAt.Format("2006-01-02 15:04:05"))
-		commentMetadata = commentMetadata + "\n" + "User: " + *comment.User.Login
-		collated = collated + "\n" + commentMetadata + "\n" + *comment.Body + "\n"
+	_, err = migrate.New(cfg).MigrateIssue(context.Background(), issue)
+	if closeErr := rep.Close(); err == nil {
+		err = closeErr
 	}
-	cBytes, err = editBodyVim("migratron.*.collate.txt", collated)
 	if err != nil {
-		return
+		return err
 	}
-
-	return
+	return runPostHook(cmd)
 }
 
-func editBodyVim(filename, body string) (file []byte, err error) {
-	tmpfile, err := ioutil.TempFile("", filename)
-	if err != nil {
-		return
+// readBlocklistFile reads the terms of --blocklist-file, if set. It is read
+// on each use, so a batch picks up edits between repo pairs.
+func readBlocklistFile() ([]string, error) {
+	if blocklistFile == "" {
+		return nil, nil
 	}
-	defer os.Remove(tmpfile.Name())
-	if _, err = tmpfile.Write([]byte(body)); err != nil {
-		tmpfile.Close()
-		return
+	f, err := os.Open(blocklistFile)
+	if err != nil {
+		return nil, err
 	}
-	if err = tmpfile.Close(); err != nil {
-		return
+	defer f.Close()
+	terms, err := migrate.ReadBlocklist(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", blocklistFile, err)
 	}
+	return terms, nil
+}
 
-	cmd := editorCmd(tmpfile.Name())
-	err = cmd.Run()
-	if err != nil {
-		return
+// withContentFlags sets the flags preparing source content on cfg
+func withContentFlags(cfg migrate.Config) migrate.Config {
+	if transformCmd != "" {
+		cfg.Transform = transform
 	}
+	cfg.URLMap = urlMap
+	cfg.RewriteInternalLinks = rewriteInternalLinks
+	cfg.OnPrivateAsset = brokenAssetAction
+	cfg.Emoji = emojiMode
+	return cfg
+}
 
-	file, err = ioutil.ReadFile(tmpfile.Name())
+// withBlocklistFile adds the terms of --blocklist-file to the default blocklist
+func withBlocklistFile(cfg migrate.Config) (migrate.Config, error) {
+	extra, err := readBlocklistFile()
 	if err != nil {
-		return
+		return cfg, err
 	}
-
-	return
+	if extra != nil {
+		cfg.Blocklist = append(append([]string{}, migrate.DefaultBlocklist...), extra...)
+	}
+	return cfg, nil
 }
 
-func editorCmd(filename string) *exec.Cmd {
-	editorPath := os.Getenv("EDITOR")
-	if editorPath == "" {
-		editorPath = default_editor
+// parseDate accepts a YYYY-MM-DD date or an RFC3339 timestamp, an empty string is the zero time
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
 	}
-	editor := exec.Command(editorPath, filename)
-
-	editor.Stdin = os.Stdin
-	editor.Stdout = os.Stdout
-	editor.Stderr = os.Stderr
-
-	return editor
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q is not a YYYY-MM-DD date or RFC3339 timestamp", ErrBadDate, s)
+	}
+	return t, nil
 }
 
 func initConfig() {
 	viper.SetEnvPrefix("MIGRATRON")
-	viper.BindEnv("TOKEN")
-	viper.BindEnv("FROM_REPO")
-	viper.BindEnv("TO_REPO")
+	viper.BindEnv("TOKEN", tokenEnv)
+	viper.BindEnv("SOURCE_TOKEN")
+	viper.BindEnv("FROM_REPO", fromRepoEnv)
+	viper.BindEnv("TO_REPO", toRepoEnv)
 
 	viper.AutomaticEnv()
 }
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/iancoffey/migratron/migrate"
+	"github.com/manifoldco/promptui"
+)
+
+const (
+	default_editor = "vim"
+)
+
+// editTmpDir is where files are edited, empty uses $TMPDIR or the OS default
+var editTmpDir string
+
+// Editor overrides, empty falls back to the next one: the body and collate
+// editors to editor, editor to $EDITOR and then default_editor
+var editor, bodyEditor, collateEditor string
+
+func init() {
+	RootCmd.PersistentFlags().StringVar(&editTmpDir, "tmpdir", "", "directory for the temporary files issues are edited in, defaults to $TMPDIR")
+	RootCmd.PersistentFlags().StringVar(&editor, "editor", "", "editor command for issue content, defaults to $EDITOR")
+	RootCmd.PersistentFlags().StringVar(&bodyEditor, "body-editor", "", "editor command for issue bodies, defaults to --editor")
+	RootCmd.PersistentFlags().StringVar(&collateEditor, "collate-editor", "", "editor command for collated comments, defaults to --editor")
+}
+
+// terminalPrompter reviews issues with promptui prompts and $EDITOR
+type terminalPrompter struct {
+	color bool
+}
+
+var _ migrate.Prompter = terminalPrompter{}
+
+func newTerminalPrompter() terminalPrompter {
+	return terminalPrompter{color: colorEnabled()}
+}
+
+// templates returns nil, promptui's colored defaults, unless color is disabled
+func (t terminalPrompter) templates() *promptui.PromptTemplates {
+	if t.color {
+		return nil
+	}
+	return plainTemplates()
+}
+
+func (t terminalPrompter) Confirm(label string) (bool, error) {
+	prompt := promptui.Prompt{
+		Label:     label,
+		IsConfirm: true,
+		Templates: t.templates(),
+	}
+	answer, err := prompt.Run()
+	if errors.Is(err, promptui.ErrAbort) {
+		return false, nil
+	}
+	if err != nil {
+		return false, promptError(err)
+	}
+	return answer == "y", nil
+}
+
+func (t terminalPrompter) Input(label, def string) (string, error) {
+	prompt := promptui.Prompt{
+		Label:     label,
+		Default:   def,
+		AllowEdit: true,
+		Templates: t.templates(),
+	}
+	answer, err := prompt.Run()
+	if err != nil {
+		return "", promptError(err)
+	}
+	return answer, nil
+}
+
+func (t terminalPrompter) Edit(name, content string) (string, error) {
+	edited, changed, err := editBodyVim(editorFor(name), "migratron.*."+name+".txt", content, t.editorFailed)
+	if err != nil {
+		return "", err
+	}
+	if !changed {
+		fmt.Fprintf(os.Stderr, "No changes made to the %s\n", name)
+	}
+	return string(edited), nil
+}
+
+// Choices offered when the editor exits with an error
+const (
+	editorReopen   = "Re-open editor"
+	editorOriginal = "Use original"
+	editorAbort    = "Abort"
+)
+
+// editorFailed asks what to do after the editor exited with err
+func (t terminalPrompter) editorFailed(err error) (string, error) {
+	prompt := promptui.Select{
+		Label: fmt.Sprintf("The editor failed (%v)", err),
+		Items: []string{editorReopen, editorOriginal, editorAbort},
+	}
+	if !t.color {
+		prompt.Templates = plainSelectTemplates()
+	}
+	_, choice, err := prompt.Run()
+	if err != nil {
+		return "", promptError(err)
+	}
+	return choice, nil
+}
+
+// promptError maps promptui's interrupt errors onto migrate.ErrUserAborted
+func promptError(err error) error {
+	if errors.Is(err, promptui.ErrInterrupt) || errors.Is(err, promptui.ErrEOF) {
+		return migrate.ErrUserAborted
+	}
+	return err
+}
+
+// editorFor returns the editor command for what is being edited
+func editorFor(name string) string {
+	switch {
+	case name == "body" && bodyEditor != "":
+		return bodyEditor
+	case name == "collate" && collateEditor != "":
+		return collateEditor
+	case editor != "":
+		return editor
+	case os.Getenv("EDITOR") != "":
+		return os.Getenv("EDITOR")
+	}
+	return default_editor
+}
+
+// editBodyVim edits body with the editor command and reports whether it was
+// changed. When the editor exits with an error, failed decides whether it is
+// re-opened on what was saved so far, the original body is used, or the edit
+// is aborted with the editor's error.
+func editBodyVim(editor, filename, body string, failed func(error) (string, error)) (file []byte, changed bool, err error) {
+	tmpfile, err := ioutil.TempFile(editTmpDir, filename)
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmpfile.Name())
+	// issue content may be confidential until reviewed
+	if err = tmpfile.Chmod(0600); err != nil {
+		tmpfile.Close()
+		return
+	}
+	if _, err = tmpfile.Write([]byte(body)); err != nil {
+		tmpfile.Close()
+		return
+	}
+	if err = tmpfile.Close(); err != nil {
+		return
+	}
+
+	for {
+		err = editorCmd(editor, tmpfile.Name()).Run()
+		var exitErr *exec.ExitError
+		if err == nil || !errors.As(err, &exitErr) {
+			break
+		}
+		var choice string
+		if choice, err = failed(exitErr); err != nil {
+			return
+		}
+		if choice == editorOriginal {
+			return []byte(body), false, nil
+		}
+		if choice == editorAbort {
+			return nil, false, exitErr
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	file, err = ioutil.ReadFile(tmpfile.Name())
+	if err != nil {
+		return
+	}
+	changed = !bytes.Equal(file, []byte(body))
+
+	return
+}
+
+// editorCmd runs editor on filename. editor may carry arguments, like "code --wait".
+func editorCmd(editor, filename string) *exec.Cmd {
+	args := append(strings.Fields(editor), filename)
+	cmd := exec.Command(args[0], args[1:]...)
+
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/iancoffey/migratron/migrate"
+	"github.com/spf13/cobra"
+)
+
+var relinkMap string
+
+func init() {
+	relinkIssuesCmd.Flags().StringVar(&relinkMap, "map", "", "a --report file mapping source issues to migrated issues")
+
+	IssuesCmd.AddCommand(relinkIssuesCmd)
+}
+
+var relinkIssuesCmd = &cobra.Command{
+	Use:   "relink",
+	Short: "rewrite #N references in migrated issues to point at the migrated issues",
+	RunE:  relinkIssues,
+}
+
+func relinkIssues(cmd *cobra.Command, args []string) error {
+	if relinkMap == "" {
+		return fmt.Errorf("%w: --map is required", migrate.ErrInvalidConfig)
+	}
+	cfg, err := repoConfig()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(relinkMap)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	results, err := migrate.ReadReport(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", relinkMap, err)
+	}
+
+	edited, err := migrate.New(cfg).Relink(context.Background(), results)
+	for _, n := range edited {
+		cmd.Printf("relinked: %d\n", n)
+	}
+	return err
+}
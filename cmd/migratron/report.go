@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iancoffey/migratron/migrate"
+	"github.com/spf13/cobra"
+)
+
+var outputFormat, reportPath string
+
+// reporter renders results to the terminal and appends them to the --report file
+type reporter struct {
+	cmd  *cobra.Command
+	json bool
+	file *os.File
+	log  *runLog
+	err  error
+
+	// security holds the issues refused for security reasons, listed on Close
+	security []migrate.Result
+	// review holds the issues left for manual review, listed on Close
+	review []migrate.Result
+	// hookErr is the first --hook-strict failure, returned by Close
+	hookErr error
+}
+
+func newReporter(cmd *cobra.Command) (*reporter, error) {
+	r := &reporter{cmd: cmd}
+	switch outputFormat {
+	case "text":
+	case "json":
+		r.json = true
+	default:
+		return nil, fmt.Errorf("%w: --output must be text or json, got %q", migrate.ErrInvalidConfig, outputFormat)
+	}
+	if reportPath != "" {
+		f, err := os.OpenFile(reportPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, err
+		}
+		r.file = f
+	}
+	if logFilePath != "" {
+		l, err := openRunLog(logFilePath, logMaxSize*1024*1024, logKeep)
+		if err != nil {
+			return nil, err
+		}
+		r.log = l
+	}
+	return r, nil
+}
+
+// output returns the writer progress messages go to, teed to the log file if there is one
+func (r *reporter) output() io.Writer {
+	if r.log == nil {
+		return r.cmd.ErrOrStderr()
+	}
+	return io.MultiWriter(r.cmd.ErrOrStderr(), r.log)
+}
+
+// event renders the progress of an issue, it is used as migrate.Config.OnEvent.
+// Migrated issues are passed to --per-issue-hook once reported.
+func (r *reporter) event(e migrate.Event) {
+	if e.Kind != migrate.EventDone {
+		return
+	}
+	r.report(e.Result)
+	if e.Result.Status != migrate.StatusMigrated {
+		return
+	}
+	if err := issueHook(e.Result); err != nil && r.hookErr == nil {
+		r.hookErr = hookFailed(r.cmd, err)
+	}
+}
+
+// report renders a result. The first write error is kept and returned by Close.
+func (r *reporter) report(res migrate.Result) {
+	if r.err != nil {
+		return
+	}
+	switch res.Status {
+	case migrate.StatusSecurity:
+		r.security = append(r.security, res)
+	case migrate.StatusReview:
+		r.review = append(r.review, res)
+	}
+	if r.file != nil {
+		if r.err = json.NewEncoder(r.file).Encode(res); r.err != nil {
+			return
+		}
+	}
+	if r.log != nil {
+		if r.err = r.log.logResult(res); r.err != nil {
+			return
+		}
+	}
+	if r.json {
+		r.err = json.NewEncoder(r.cmd.OutOrStdout()).Encode(res)
+		return
+	}
+
+	switch res.Status {
+	case migrate.StatusMigrated:
+		r.cmd.Print("\n-------------------------------\n")
+		r.cmd.Printf("Successfully migrated issue %d to:\n", res.Source)
+		r.cmd.Println(res.DestURL)
+		if len(res.LabelCollisions) > 0 {
+			r.cmd.Printf("Labels that collided with a different target label: %s\n", strings.Join(res.LabelCollisions, ", "))
+		}
+		if len(res.DroppedLabels) > 0 {
+			r.cmd.Printf("Dropped labels that could not be created: %s\n", strings.Join(res.DroppedLabels, ", "))
+		}
+		if len(res.PrivateAssets) > 0 {
+			r.cmd.Printf("Attachments of the private source that public viewers can not load: %s\n", strings.Join(res.PrivateAssets, ", "))
+		}
+		if len(res.EnforcedLabels) > 0 {
+			r.cmd.Printf("Added required labels: %s\n", strings.Join(res.EnforcedLabels, ", "))
+		}
+		r.cmd.Printf("Please review each issue for accuracy")
+		r.cmd.Print("\n-------------------------------\n\n")
+	case migrate.StatusDeclined:
+	case migrate.StatusFailed:
+		r.cmd.Printf("failed: %d: %s\n", res.Source, res.Error)
+	case migrate.StatusSecurity, migrate.StatusReview:
+	default:
+		r.cmd.Printf("%s: %d\n", res.Status, res.Source)
+	}
+}
+
+// printStats summarizes where the time of a run went
+func (r *reporter) printStats(stats migrate.Stats, wall time.Duration) {
+	r.cmd.Printf("\nRun took %s, %s waiting on rate limits\n", wall.Round(time.Second), stats.RateLimitWait.Round(time.Second))
+	r.cmd.Printf("%d API calls, %d issues reviewed at %s on average\n", stats.APICalls, stats.Issues, stats.AvgIssueTime().Round(100*time.Millisecond))
+	statuses := make([]string, 0, len(stats.Outcomes))
+	for status := range stats.Outcomes {
+		statuses = append(statuses, string(status))
+	}
+	sort.Strings(statuses)
+	for i, status := range statuses {
+		statuses[i] = fmt.Sprintf("%s %d", status, stats.Outcomes[migrate.Status(status)])
+	}
+	if len(statuses) > 0 {
+		r.cmd.Printf("Outcomes: %s\n", strings.Join(statuses, ", "))
+	}
+}
+
+// Close lists the issues refused for security reasons or left for review and closes the report file
+func (r *reporter) Close() error {
+	if len(r.security) > 0 && !r.json {
+		r.cmd.Println("\nIssues refused for security reasons, review them before using --allow-security:")
+		for _, res := range r.security {
+			r.cmd.Printf("  %s: %s\n", res.SourceURL, res.Error)
+		}
+	}
+	if len(r.review) > 0 && !r.json {
+		r.cmd.Println("\nIssues left for manual review, migrate them interactively:")
+		for _, res := range r.review {
+			r.cmd.Printf("  %s: %s\n", res.SourceURL, res.Error)
+		}
+	}
+	if r.file != nil {
+		if err := r.file.Close(); err != nil && r.err == nil {
+			r.err = err
+		}
+	}
+	if r.log != nil {
+		if err := r.log.Close(); err != nil && r.err == nil {
+			r.err = err
+		}
+	}
+	if r.err == nil {
+		return r.hookErr
+	}
+	return r.err
+}
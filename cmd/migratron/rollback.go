@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/iancoffey/migratron/migrate"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rollbackReport string
+	rollbackDelete bool
+)
+
+func init() {
+	rollbackIssuesCmd.Flags().StringVar(&rollbackReport, "report", "", "the --report file of the migration to roll back")
+	rollbackIssuesCmd.Flags().StringVar(&migratedToLabel, "to-label", migrate.DefaultMigratedToLabel, "label marking the migrated source issues")
+	rollbackIssuesCmd.Flags().BoolVar(&rollbackDelete, "delete", false, "delete the target issues instead of closing them, where the token has admin access")
+
+	IssuesCmd.AddCommand(rollbackIssuesCmd)
+}
+
+var rollbackIssuesCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "close or delete the target issues of a migration and unmark their source issues",
+	RunE:  rollbackIssues,
+}
+
+func rollbackIssues(cmd *cobra.Command, args []string) error {
+	if rollbackReport == "" {
+		return fmt.Errorf("%w: --report is required", migrate.ErrInvalidConfig)
+	}
+	cfg, err := repoConfig()
+	if err != nil {
+		return err
+	}
+	cfg.MigratedToLabel = migratedToLabel
+	cfg.Prompter = newTerminalPrompter()
+	cfg.Out = os.Stderr
+
+	f, err := os.Open(rollbackReport)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	results, err := migrate.ReadReport(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", rollbackReport, err)
+	}
+
+	rolledBack, err := migrate.New(cfg).Rollback(context.Background(), results, rollbackDelete)
+	for _, n := range rolledBack {
+		cmd.Printf("rolled back: %d\n", n)
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/iancoffey/migratron/migrate"
+)
+
+// runState is persisted between runs in the --state-file
+type runState struct {
+	// LastMigration holds the start time of the last successful run, keyed by "from->to"
+	LastMigration map[string]time.Time `json:"last_migration"`
+}
+
+func stateKey(from, to migrate.Repo) string {
+	return from.String() + "->" + to.String()
+}
+
+// loadState reads the state file, a missing file is an empty state
+func loadState(path string) (*runState, error) {
+	s := &runState{LastMigration: map[string]time.Time{}}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, err
+	}
+	if s.LastMigration == nil {
+		s.LastMigration = map[string]time.Time{}
+	}
+	return s, nil
+}
+
+func (s *runState) save(path string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0600)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/iancoffey/migratron/migrate"
+	"github.com/spf13/cobra"
+)
+
+var statsJSON bool
+
+func init() {
+	statsIssuesCmd.Flags().BoolVar(&statsJSON, "json", false, "print the tallies as JSON")
+	statsIssuesCmd.Flags().StringVar(&blocklistFile, "blocklist-file", "", "file of extra internal terms, one per line, # for comments and re: for regular expressions")
+
+	IssuesCmd.AddCommand(statsIssuesCmd)
+}
+
+var statsIssuesCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "tally the issues of the source repo without migrating anything",
+	RunE:  statsIssues,
+}
+
+func statsIssues(cmd *cobra.Command, args []string) error {
+	cfg, err := repoConfig()
+	if err != nil {
+		return err
+	}
+	if cfg, err = withBlocklistFile(cfg); err != nil {
+		return err
+	}
+
+	stats, err := migrate.New(cfg).RepoStats(context.Background())
+	if err != nil {
+		return err
+	}
+	if statsJSON {
+		return json.NewEncoder(cmd.OutOrStdout()).Encode(stats)
+	}
+	cmd.Printf("%s: %d issues, %d open and %d closed, and %d pull requests\n", cfg.From, stats.Total, stats.Open, stats.Closed, stats.PullRequests)
+	cmd.Printf("%d carry the skip label, %d the migrated label\n", stats.SkipLabelled, stats.MigratedLabelled)
+	cmd.Printf("%d hold internal terms in their title or body\n", stats.Internal)
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// transformCmd is run on every body and comment before migration, see transform
+var transformCmd string
+
+// transform pipes content through --transform-cmd: the content is written to
+// its stdin and whatever it writes to stdout replaces it. MIGRATRON_TRANSFORM_KIND
+// is set to body or comment. A non-zero exit fails the issue.
+func transform(kind, content string) (string, error) {
+	args := strings.Fields(transformCmd)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), "MIGRATRON_TRANSFORM_KIND="+kind)
+	cmd.Stdin = strings.NewReader(content)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("transform %s: %v: %s", kind, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -4,10 +4,11 @@ go 1.16
 
 require (
 	github.com/dharmeshkakadia/cobra-example v0.0.0-20170912070740-5274e46f9c94 // indirect
-	github.com/google/go-github/v36 v36.0.0 // indirect
-	github.com/manifoldco/promptui v0.8.0 // indirect
+	github.com/google/go-github/v36 v36.0.0
+	github.com/manifoldco/promptui v0.8.0
 	github.com/mitchellh/go-homedir v1.1.0 // indirect
-	github.com/spf13/cobra v1.2.1 // indirect
-	github.com/spf13/viper v1.8.1 // indirect
-	golang.org/x/oauth2 v0.0.0-20210628180205-a41e5a781914 // indirect
+	github.com/spf13/cobra v1.2.1
+	github.com/spf13/viper v1.8.1
+	golang.org/x/oauth2 v0.0.0-20210628180205-a41e5a781914
+	gopkg.in/yaml.v2 v2.4.0
 )
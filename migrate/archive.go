@@ -0,0 +1,36 @@
+package migrate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// writeArchive writes the migrated title and body of issue to a markdown file
+// named after its source number in MarkdownDir. destURL is empty when no
+// target issue was created.
+func (m *Migrator) writeArchive(issue *github.Issue, req *github.IssueRequest, destURL string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", req.GetTitle())
+	fmt.Fprintf(&b, "- Source: %s\n", issue.GetHTMLURL())
+	if destURL != "" {
+		fmt.Fprintf(&b, "- Migrated to: %s\n", destURL)
+	}
+	fmt.Fprintf(&b, "- State: %s\n", issue.GetState())
+	fmt.Fprintf(&b, "- Opened by %s on %s\n", issue.GetUser().GetLogin(), issue.GetCreatedAt().Format("2006-01-02"))
+	if req.Labels != nil && len(*req.Labels) > 0 {
+		fmt.Fprintf(&b, "- Labels: %s\n", strings.Join(*req.Labels, ", "))
+	}
+	fmt.Fprintf(&b, "\n%s\n", req.GetBody())
+
+	if err := os.MkdirAll(m.cfg.MarkdownDir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(m.cfg.MarkdownDir, strconv.Itoa(issue.GetNumber())+".md")
+	return ioutil.WriteFile(path, []byte(b.String()), 0644)
+}
@@ -0,0 +1,102 @@
+package migrate
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// Policies for Config.OnPrivateAsset, applied to attachments of a private
+// source that public viewers of the target can not load
+const (
+	// AssetActionWarn reports them and migrates them unchanged
+	AssetActionWarn = "warn"
+	// AssetActionPlaceholder replaces them by PrivateAssetPlaceholder
+	AssetActionPlaceholder = "placeholder"
+)
+
+// PrivateAssetPlaceholder replaces private attachments with AssetActionPlaceholder
+const PrivateAssetPlaceholder = "[attachment of the private source repo removed]"
+
+// privateAsset reports whether link is an image or attachment uploaded to the
+// source, which only those who can read the source can load
+func (m *Migrator) privateAsset(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	host, path := strings.ToLower(u.Host), strings.ToLower(u.Path)
+	if host == "private-user-images.githubusercontent.com" {
+		return true
+	}
+	repo := "/" + strings.ToLower(m.cfg.From.String()) + "/"
+	if host == "raw.githubusercontent.com" {
+		return strings.HasPrefix(path, repo)
+	}
+	if host != m.sourceWebHost() {
+		return false
+	}
+	for _, prefix := range []string{"/user-attachments/", "/storage/", repo + "assets/", repo + "files/"} {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceWebHost is the host the source repo's pages are served from
+func (m *Migrator) sourceWebHost() string {
+	if m.cfg.SourceBaseURL != nil {
+		return strings.ToLower(m.cfg.SourceBaseURL.Host)
+	}
+	return "github.com"
+}
+
+// privateAssets returns the distinct private attachments linked from the
+// body and comments of a private source issue
+func (m *Migrator) privateAssets(issue *github.Issue, comments []*github.IssueComment) []string {
+	if !m.sourcePrivate {
+		return nil
+	}
+	var assets []string
+	seen := map[string]bool{}
+	add := func(s string) {
+		for _, link := range linkRe.FindAllString(s, -1) {
+			if !seen[link] && m.privateAsset(link) {
+				seen[link] = true
+				assets = append(assets, link)
+			}
+		}
+	}
+	add(issue.GetBody())
+	for _, c := range comments {
+		add(c.GetBody())
+	}
+	return assets
+}
+
+// replacePrivateAssets returns copies of issue and its comments with their
+// private attachments replaced by PrivateAssetPlaceholder
+func (m *Migrator) replacePrivateAssets(issue *github.Issue, comments []*github.IssueComment) (*github.Issue, []*github.IssueComment) {
+	replace := func(s string) string {
+		return linkRe.ReplaceAllStringFunc(s, func(link string) string {
+			if m.privateAsset(link) {
+				return PrivateAssetPlaceholder
+			}
+			return link
+		})
+	}
+	body := replace(issue.GetBody())
+	replaced := *issue
+	replaced.Body = &body
+
+	out := make([]*github.IssueComment, len(comments))
+	for i, c := range comments {
+		cb := replace(c.GetBody())
+		rc := *c
+		rc.Body = &cb
+		out[i] = &rc
+	}
+	return &replaced, out
+}
@@ -0,0 +1,108 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// Policies for Config.OnAssigneeError, applied when a source assignee can not
+// be assigned in the target
+const (
+	// AssigneeErrorDrop migrates the issue without them
+	AssigneeErrorDrop = "drop"
+	// AssigneeErrorSkip skips the issue
+	AssigneeErrorSkip = "skip"
+	// AssigneeErrorFail stops the run
+	AssigneeErrorFail = "fail"
+)
+
+// unassignable returns the source assignees of issue that can not be assigned in the target
+func (m *Migrator) unassignable(ctx context.Context, issue *github.Issue) ([]string, error) {
+	var logins []string
+	for _, u := range issue.Assignees {
+		ok, err := m.assignable(ctx, u.GetLogin())
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			logins = append(logins, u.GetLogin())
+		}
+	}
+	return logins, nil
+}
+
+// mapAssignees returns the source assignees and, with ExpandTeams, the
+// members of mentioned teams that can be assigned in the target, or the
+// fallback assignee when none of them can. The note names team members
+// that could not be assigned.
+func (m *Migrator) mapAssignees(ctx context.Context, issue *github.Issue) (mapped []string, note string, err error) {
+	seen := map[string]bool{}
+	add := func(login string) {
+		if !seen[strings.ToLower(login)] {
+			seen[strings.ToLower(login)] = true
+			mapped = append(mapped, login)
+		}
+	}
+	if m.cfg.SyncAssignees {
+		for _, u := range issue.Assignees {
+			ok, err := m.assignable(ctx, u.GetLogin())
+			if err != nil {
+				return nil, "", err
+			}
+			if !ok {
+				m.printf("%s can not be assigned in %s, dropping them from issue %d\n", u.GetLogin(), m.cfg.To, issue.GetNumber())
+				continue
+			}
+			add(u.GetLogin())
+		}
+	}
+	if m.cfg.ExpandTeams {
+		members, teamNote, err := m.teamAssignees(ctx, issue)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, login := range members {
+			add(login)
+		}
+		note = teamNote
+	}
+	if len(mapped) == 0 && m.cfg.AssigneeFallback != "" {
+		mapped = []string{m.cfg.AssigneeFallback}
+	}
+	return mapped, note, nil
+}
+
+// assignable reports whether login can be assigned to issues in the target
+func (m *Migrator) assignable(ctx context.Context, login string) (bool, error) {
+	if ok, seen := m.assignees[login]; seen {
+		return ok, nil
+	}
+	ok, _, err := m.client.Issues.IsAssignee(ctx, m.cfg.To.Owner, m.cfg.To.Name, login)
+	if err != nil {
+		return false, newAPIError("check assignee", err)
+	}
+	if m.assignees == nil {
+		m.assignees = map[string]bool{}
+	}
+	m.assignees[login] = ok
+	return ok, nil
+}
+
+// checkAssigneeFallback verifies the fallback assignee is a collaborator on the target
+func (m *Migrator) checkAssigneeFallback(ctx context.Context) error {
+	if m.cfg.AssigneeFallback == "" {
+		return nil
+	}
+	to := m.cfg.To
+	ok, _, err := m.client.Repositories.IsCollaborator(ctx, to.Owner, to.Name, m.cfg.AssigneeFallback)
+	if err != nil {
+		return newAPIError("check collaborator", err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: fallback assignee %s is not a collaborator on %s", ErrInvalidConfig, m.cfg.AssigneeFallback, to)
+	}
+	return nil
+}
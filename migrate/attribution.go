@@ -0,0 +1,77 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// DefaultCommentAttribution renders a collated comment as the date, author and body
+const DefaultCommentAttribution = "\nContext from {{.CreatedAt}}\nUser: {{.Author}}\n{{.Body}}\n"
+
+// InlineCommentAttribution renders a collated comment as its body prefixed
+// with its author in bold. The author has no @ so they are not mentioned.
+const InlineCommentAttribution = "\n**{{.Author}}:** {{.Body}}\n"
+
+// Styles for Config.CommentStyle, picking the attribution used when
+// CommentAttribution is unset
+const (
+	CommentStyleBlock  = "block"
+	CommentStyleInline = "inline"
+)
+
+// attributionFields are the fields available to Config.CommentAttribution
+type attributionFields struct {
+	Author    string
+	CreatedAt string
+	URL       string
+	Body      string
+}
+
+// parseAttribution parses the CommentAttribution template and renders a
+// sample comment with it, so mistakes surface before any prompt
+func parseAttribution(text string) (*template.Template, error) {
+	t, err := template.New("attribution").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: comment attribution: %v", ErrInvalidConfig, err)
+	}
+	if err := t.Execute(&strings.Builder{}, attributionFields{}); err != nil {
+		return nil, fmt.Errorf("%w: comment attribution: %v", ErrInvalidConfig, err)
+	}
+	return t, nil
+}
+
+// Policies for Config.OnLargeComment
+const (
+	LargeCommentCollapse = "collapse"
+	LargeCommentTruncate = "truncate"
+)
+
+// attribute renders comment with the attribution template
+func (m *Migrator) attribute(comment *github.IssueComment) (string, error) {
+	var b strings.Builder
+	err := m.attribution.Execute(&b, attributionFields{
+		Author:    comment.GetUser().GetLogin(),
+		CreatedAt: comment.GetCreatedAt().Format("2006-01-02 15:04:05"),
+		URL:       comment.GetHTMLURL(),
+		Body:      m.fitComment(comment),
+	})
+	return b.String(), err
+}
+
+// fitComment returns the body of comment, collapsed or truncated per
+// OnLargeComment when it is longer than CommentMaxSize
+func (m *Migrator) fitComment(comment *github.IssueComment) string {
+	body := []rune(comment.GetBody())
+	if m.cfg.CommentMaxSize <= 0 || len(body) <= m.cfg.CommentMaxSize {
+		return string(body)
+	}
+	if m.cfg.OnLargeComment == LargeCommentTruncate {
+		m.printf("Comment %s is %d characters, truncating it to %d\n", comment.GetHTMLURL(), len(body), m.cfg.CommentMaxSize)
+		return closeFence(string(body[:m.cfg.CommentMaxSize])) + fmt.Sprintf("\n\n_...truncated, see %s_", comment.GetHTMLURL())
+	}
+	m.printf("Comment %s is %d characters, collapsing it\n", comment.GetHTMLURL(), len(body))
+	return fmt.Sprintf("<details>\n<summary>Long comment, %d characters (click to expand)</summary>\n\n%s\n</details>", len(body), string(body))
+}
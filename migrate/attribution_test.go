@@ -0,0 +1,35 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v36/github"
+)
+
+func TestFitCommentClosesCutFence(t *testing.T) {
+	m := New(Config{CommentMaxSize: 100, OnLargeComment: LargeCommentTruncate})
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"backticks", "Stack trace:\n```go\n" + strings.Repeat("goroutine 1 [running]:\n", 10) + "```"},
+		{"long tildes", "Stack trace:\n~~~~\n" + strings.Repeat("goroutine 1 [running]:\n", 10) + "~~~~"},
+		{"prose", strings.Repeat("word ", 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := m.fitComment(&github.IssueComment{Body: &tt.body, HTMLURL: github.String("https://github.com/acme/internal/issues/1#issuecomment-1")})
+			note := strings.Index(got, "_...truncated, see")
+			if note < 0 {
+				t.Fatalf("fitComment(%q) = %q, want a truncation note", tt.body, got)
+			}
+			if _, _, open := splitFenced(got[:note]); open {
+				t.Errorf("fitComment(%q) = %q, leaving the code block open before the note", tt.body, got)
+			}
+			if !strings.HasPrefix(got, tt.body[:100]) {
+				t.Errorf("fitComment(%q) = %q, want it to keep the first 100 characters", tt.body, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// reviewReason explains why AutoClean leaves an issue for manual review,
+// or returns "" if it can be migrated unattended
+func (m *Migrator) reviewReason(ctx context.Context, issue *github.Issue, comments []*github.IssueComment) (string, error) {
+	recent, _ := m.filterComments(issue, comments)
+	if m.flaggedInternal(issue, recent) {
+		return "holds internal terms", nil
+	}
+	if m.cfg.SyncAssignees && !m.cfg.NoTarget {
+		logins, err := m.unassignable(ctx, issue)
+		if err != nil {
+			return "", err
+		}
+		if len(logins) > 0 {
+			return fmt.Sprintf("has assignees %s who can not be assigned in %s", strings.Join(logins, ", "), m.cfg.To), nil
+		}
+	}
+	return "", nil
+}
+
+// generateAutoRequest builds the issue request without prompting: the title
+// and body unchanged, labels synced and every remaining comment collated
+func (m *Migrator) generateAutoRequest(ctx context.Context, issue *github.Issue, comments []*github.IssueComment) (*github.IssueRequest, error) {
+	synced := m.assertAndSyncLabels(issue.Labels)
+	req := &github.IssueRequest{
+		Title:  issue.Title,
+		Body:   issue.Body,
+		Labels: &synced,
+	}
+
+	recent, note := m.filterComments(issue, comments)
+	var collated string
+	seen := map[string]bool{}
+	for _, comment := range recent {
+		if m.cfg.CommentDedup {
+			key := normalizeComment(comment.GetBody())
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		attributed, err := m.attribute(comment)
+		if err != nil {
+			return nil, err
+		}
+		collated = collated + "\n" + attributed
+	}
+	if collated = note + collated; len(collated) > 0 {
+		body := req.GetBody() + m.collatedSection(issue, comments, collated)
+		req.Body = &body
+	}
+
+	if err := m.normalizeRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
@@ -0,0 +1,122 @@
+package migrate
+
+import "strings"
+
+// Code block policies, deciding how Blocklist terms inside fenced code
+// blocks are matched
+const (
+	// CodeBlockScan matches terms in code blocks like in prose
+	CodeBlockScan = "scan"
+	// CodeBlockStrict also matches literal terms in code blocks regardless
+	// of case, as logs and configs often spell hostnames differently
+	CodeBlockStrict = "strict"
+	// CodeBlockIgnore does not look for terms inside code blocks
+	CodeBlockIgnore = "ignore"
+)
+
+// fence returns the fence marker a line opens or closes a code block with,
+// or "". Up to three spaces of indentation are allowed, like in CommonMark.
+func fence(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return ""
+	}
+	for _, c := range []string{"`", "~"} {
+		n := len(trimmed) - len(strings.TrimLeft(trimmed, c))
+		if n >= 3 {
+			return trimmed[:n]
+		}
+	}
+	return ""
+}
+
+// Kinds of markdown lines told apart by classifyLines
+type lineKind int
+
+const (
+	proseLine lineKind = iota
+	fenceLine
+	codeLine
+)
+
+// classifyLines splits markdown into lines and tells prose from fence
+// delimiters and the content of fenced code blocks. An unclosed block runs
+// to the end, and open is the fence it was opened with.
+func classifyLines(s string) (lines []string, kinds []lineKind, open string) {
+	lines = strings.Split(s, "\n")
+	kinds = make([]lineKind, len(lines))
+	for i, line := range lines {
+		f := fence(line)
+		switch {
+		case open == "" && f != "":
+			open = f
+			kinds[i] = fenceLine
+		case open != "" && f != "" && f[0] == open[0] && len(f) >= len(open) && strings.TrimSpace(line) == f:
+			open = ""
+			kinds[i] = fenceLine
+		case open != "":
+			kinds[i] = codeLine
+		}
+	}
+	return lines, kinds, open
+}
+
+// closeFence returns s with the fenced code block it leaves open, if any,
+// closed, so text appended to it renders as prose
+func closeFence(s string) string {
+	_, _, open := classifyLines(s)
+	if open == "" {
+		return s
+	}
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	return s + open
+}
+
+// splitFenced splits markdown into its prose and the content of its fenced
+// code blocks. An unclosed block runs to the end, and openFence reports it.
+func splitFenced(s string) (prose, code string, openFence bool) {
+	lines, kinds, open := classifyLines(s)
+	var p, c []string
+	for i, line := range lines {
+		switch kinds[i] {
+		case proseLine:
+			p = append(p, line)
+		case codeLine:
+			c = append(c, line)
+		}
+	}
+	return strings.Join(p, "\n"), strings.Join(c, "\n"), open != ""
+}
+
+// mapProse applies f to each run of prose lines of markdown, leaving fenced
+// code blocks as they are
+func mapProse(s string, f func(string) string) string {
+	lines, kinds, _ := classifyLines(s)
+	var out, run []string
+	flush := func() {
+		if len(run) > 0 {
+			out = append(out, f(strings.Join(run, "\n")))
+			run = nil
+		}
+	}
+	for i, line := range lines {
+		if kinds[i] == proseLine {
+			run = append(run, line)
+			continue
+		}
+		flush()
+		out = append(out, line)
+	}
+	flush()
+	return strings.Join(out, "\n")
+}
+
+// brokeFence reports whether editing left a code block open that was
+// closed in the original, which would render the rest of the text as code
+func brokeFence(original, edited string) bool {
+	_, _, before := splitFenced(original)
+	_, _, after := splitFenced(edited)
+	return after && !before
+}
@@ -0,0 +1,139 @@
+package migrate
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// graphqlCommentsPerIssue is the number of comments fetched with each issue,
+// issues with more fall back to REST
+const graphqlCommentsPerIssue = 100
+
+// issueComments returns every comment of a source issue, from the GraphQL
+// prefetch when it holds them
+func (m *Migrator) issueComments(ctx context.Context, number int) ([]*github.IssueComment, error) {
+	if c, ok := m.comments[number]; ok {
+		return c, nil
+	}
+	from := m.cfg.From
+	var comments []*github.IssueComment
+	opts := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	for {
+		c, resp, err := m.source.Issues.ListComments(ctx, from.Owner, from.Name, number, opts)
+		if err != nil {
+			return nil, newAPIError("list comments", err)
+		}
+		comments = append(comments, c...)
+		if resp.NextPage == 0 {
+			return comments, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// graphqlIssuePage is the data of one page of the issues with comments query
+type graphqlIssuePage struct {
+	Repository struct {
+		Issues struct {
+			PageInfo struct {
+				HasNextPage bool   `json:"hasNextPage"`
+				EndCursor   string `json:"endCursor"`
+			} `json:"pageInfo"`
+			Nodes []struct {
+				Number   int `json:"number"`
+				Comments struct {
+					TotalCount int              `json:"totalCount"`
+					Nodes      []graphqlComment `json:"nodes"`
+				} `json:"comments"`
+			} `json:"nodes"`
+		} `json:"issues"`
+	} `json:"repository"`
+}
+
+type graphqlComment struct {
+	DatabaseID int64     `json:"databaseId"`
+	Body       string    `json:"body"`
+	URL        string    `json:"url"`
+	CreatedAt  time.Time `json:"createdAt"`
+	Author     *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// toIssueComment converts a GraphQL comment to the REST type the migration works with
+func (c graphqlComment) toIssueComment() *github.IssueComment {
+	login := "ghost"
+	if c.Author != nil {
+		login = c.Author.Login
+	}
+	return &github.IssueComment{
+		ID:        &c.DatabaseID,
+		Body:      &c.Body,
+		HTMLURL:   &c.URL,
+		CreatedAt: &c.CreatedAt,
+		User:      &github.User{Login: &login},
+	}
+}
+
+// commentsFromPage collects the comments of the issues of a page whose
+// comments were all fetched
+func commentsFromPage(page graphqlIssuePage, into map[int][]*github.IssueComment) {
+	for _, issue := range page.Repository.Issues.Nodes {
+		if issue.Comments.TotalCount > len(issue.Comments.Nodes) {
+			continue
+		}
+		comments := []*github.IssueComment{}
+		for _, c := range issue.Comments.Nodes {
+			comments = append(comments, c.toIssueComment())
+		}
+		into[issue.Number] = comments
+	}
+}
+
+// prefetchComments loads the comments of every source issue in the given
+// states with a paginated GraphQL query, instead of a REST call per issue.
+// On any GraphQL error it returns nil and the migration falls back to REST.
+func (m *Migrator) prefetchComments(ctx context.Context, states []string) map[int][]*github.IssueComment {
+	comments := map[int][]*github.IssueComment{}
+	vars := map[string]interface{}{
+		"owner":    m.cfg.From.Owner,
+		"name":     m.cfg.From.Name,
+		"states":   states,
+		"comments": graphqlCommentsPerIssue,
+		"cursor":   nil,
+	}
+	for {
+		var page graphqlIssuePage
+		err := m.graphql(ctx, m.source, `query($owner: String!, $name: String!, $states: [IssueState!], $comments: Int!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    issues(first: 50, after: $cursor, states: $states) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        number
+        comments(first: $comments) {
+          totalCount
+          nodes { databaseId body url createdAt author { login } }
+        }
+      }
+    }
+  }
+}`, vars, &page)
+		if err != nil {
+			m.printf("GraphQL comment fetch failed, falling back to REST: %v\n", err)
+			return nil
+		}
+		commentsFromPage(page, comments)
+
+		info := page.Repository.Issues.PageInfo
+		if !info.HasNextPage {
+			return comments
+		}
+		vars["cursor"] = info.EndCursor
+	}
+}
@@ -0,0 +1,143 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// serveGraphQLPages answers the comments query with the page of the fixture
+// file keyed by the request's cursor, "" for the first page
+func serveGraphQLPages(t *testing.T, fixture string) (http.HandlerFunc, *[]string) {
+	t.Helper()
+	data, err := ioutil.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pages map[string]json.RawMessage
+	if err := json.Unmarshal(data, &pages); err != nil {
+		t.Fatal(err)
+	}
+	var cursors []string
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables struct {
+				Cursor *string `json:"cursor"`
+			} `json:"variables"`
+		}
+		readJSON(r, &req)
+		cursor := ""
+		if req.Variables.Cursor != nil {
+			cursor = *req.Variables.Cursor
+		}
+		cursors = append(cursors, cursor)
+		page, ok := pages[cursor]
+		if !ok {
+			writeJSON(w, map[string]interface{}{"errors": []graphqlError{{Message: "unknown cursor " + cursor}}})
+			return
+		}
+		w.Write(page)
+	}, &cursors
+}
+
+func TestPrefetchComments(t *testing.T) {
+	f := newFakeGitHub(t)
+	var cursors *[]string
+	f.graphql, cursors = serveGraphQLPages(t, "testdata/comments_graphql.json")
+	m := newTestMigrator(t, f, Config{UseGraphQL: true})
+
+	comments := m.prefetchComments(context.Background(), []string{"OPEN"})
+	if want := []string{"", "Y3Vyc29yOjI="}; !reflect.DeepEqual(*cursors, want) {
+		t.Errorf("queried cursors %q, want %q", *cursors, want)
+	}
+
+	got := map[int][]string{}
+	for n, c := range comments {
+		got[n] = []string{}
+		for _, comment := range c {
+			got[n] = append(got[n], fmt.Sprintf("%d %s: %s", comment.GetID(), comment.GetUser().GetLogin(), comment.GetBody()))
+		}
+	}
+	want := map[int][]string{
+		1: {"101 alice: Seeing this too.", "102 ghost: Fixed on main."},
+		2: {},
+		3: {"301 bob: Duplicate of #1."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("prefetched comments %q, want %q", got, want)
+	}
+}
+
+func TestIssueCommentsFallsBackToREST(t *testing.T) {
+	f := newFakeGitHub(t)
+	for i := 0; i < 4; i++ {
+		f.addIssue(testSource, &github.Issue{Title: github.String(fmt.Sprintf("Issue %d", i+1))})
+	}
+	source := f.repo(testSource)
+	for n, count := range map[int]int{1: 3, 4: 150} {
+		for i := 1; i <= count; i++ {
+			source.comments[n] = append(source.comments[n], &github.IssueComment{Body: github.String(fmt.Sprintf("REST comment %d", i))})
+		}
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		graphql http.HandlerFunc
+		number  int
+		want    int
+	}{
+		{
+			name: "graphql error",
+			graphql: func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, map[string]interface{}{"errors": []graphqlError{{Message: "Something went wrong"}}})
+			},
+			number: 1,
+			want:   3,
+		},
+		{
+			name: "graphql server error",
+			graphql: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			number: 1,
+			want:   3,
+		},
+		{
+			name:   "comments beyond the first page",
+			number: 4,
+			want:   150,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f.graphql = tt.graphql
+			if f.graphql == nil {
+				f.graphql, _ = serveGraphQLPages(t, "testdata/comments_graphql.json")
+			}
+			m := newTestMigrator(t, f, Config{UseGraphQL: true})
+			m.comments = m.prefetchComments(ctx, []string{"OPEN"})
+			if tt.graphql != nil && m.comments != nil {
+				t.Errorf("prefetch returned %d issues, want nil after a failure", len(m.comments))
+			}
+			c, err := m.issueComments(ctx, tt.number)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(c) != tt.want {
+				t.Fatalf("issue %d has %d comments, want %d", tt.number, len(c), tt.want)
+			}
+			for i, comment := range c {
+				if want := fmt.Sprintf("REST comment %d", i+1); comment.GetBody() != want {
+					t.Errorf("issue %d comment %d is %q, want %q", tt.number, i+1, comment.GetBody(), want)
+				}
+			}
+		})
+	}
+}
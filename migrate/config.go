@@ -0,0 +1,319 @@
+package migrate
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Defaults used when the corresponding Config field is left empty
+var (
+	DefaultMigratedToLabel    = "migration/migrated"
+	DefaultMigratedFromLabel  = "migration/imported"
+	DefaultSkipLabel          = "migration/selfservice"
+	DefaultSecurityLabel      = "security"
+	DefaultUserAgent          = "migratron"
+	DefaultPendingReviewLabel = "pending-review"
+	DefaultBannedLabels       = []string{"migration/essential"}
+	DefaultBlocklist          = []string{"jira", "confluence.eng", "drive.google", "slack.com", "miro.com"}
+)
+
+// Config controls a Migrator
+type Config struct {
+	// Token authenticates every GitHub API request
+	Token string
+	// HTTPClient is the client requests are sent with, its transport is
+	// wrapped to add the token. Nil uses http.DefaultTransport.
+	HTTPClient *http.Client
+	// SourceBaseURL and TargetBaseURL are the API roots of GitHub Enterprise
+	// Server instances, like https://ghe.example.com/api/v3/. Nil uses github.com.
+	SourceBaseURL *url.URL
+	TargetBaseURL *url.URL
+	// SourceToken authenticates source requests when the source lives on
+	// another instance than the target, empty uses Token
+	SourceToken string
+	// UserAgent is sent with every GitHub API request
+	UserAgent string
+
+	From Repo
+	To   Repo
+	// Login is the GitHub login of the user running the migration
+	Login string
+
+	// MigratedToLabel is applied to source issues once migrated
+	MigratedToLabel string
+	// MigratedFromLabel is applied to issues created in the target
+	MigratedFromLabel string
+	// SourceReadOnly never writes to the source: no backlink comment,
+	// migrated label or pruning. Re-runs find migrated issues by provenance.
+	SourceReadOnly bool
+	// PruneSourceLabels are removed from source issues once migrated
+	PruneSourceLabels []string
+	// SkipLabel marks source issues that must not be migrated
+	SkipLabel string
+	// SecurityLabel marks source issues holding security sensitive details
+	SecurityLabel string
+	// AllowSecurity migrates issues with the security label or advisory links
+	// instead of refusing them
+	AllowSecurity bool
+	// BannedLabels are never synced to the target
+	BannedLabels []string
+	// LabelMap renames source labels in the target
+	LabelMap map[string]string
+	// LabelColors sets the hex color of target labels by name when they are
+	// created, reused or updated, overriding the source color
+	LabelColors map[string]string
+	// StripLabelPrefixes are removed from the start of synced label names
+	StripLabelPrefixes []string
+	// OnLabelCollision is LabelCollisionReuse, LabelCollisionRename or
+	// LabelCollisionUpdate and decides what happens when a synced label
+	// exists in the target with a different color or description
+	OnLabelCollision string
+	// OnLabelError is LabelErrorSkip, LabelErrorWarn or LabelErrorFail and
+	// decides what happens when a synced label can not be created
+	OnLabelError string
+	// OnInvalidLabel is InvalidLabelSkip, InvalidLabelFail or
+	// InvalidLabelSanitize and decides what happens to synced label names
+	// GitHub would reject, such as empty or over-long ones
+	OnInvalidLabel string
+	// Blocklist holds terms that mark content as internal. Terms prefixed
+	// with re: are regular expressions.
+	Blocklist []string
+	// ConfirmPhrase must be typed to migrate an issue with internal terms,
+	// instead of a y/N confirmation. {number} is replaced by the issue number.
+	ConfirmPhrase string
+	// ConfirmTarget requires typing the target org/repo before MigrateAll
+	// starts, even when AllOptions.Yes is set
+	ConfirmTarget bool
+	// CodeBlockPolicy is CodeBlockScan, CodeBlockStrict or CodeBlockIgnore
+	// and decides how Blocklist terms in fenced code blocks are matched
+	CodeBlockPolicy string
+	// CodeFenceGuard asks to edit again when an edit leaves a code block
+	// open that was closed before
+	CodeFenceGuard bool
+	// FailOnInternal scans every issue to be migrated for Blocklist terms
+	// first and aborts with an InternalContentError if any are found
+	FailOnInternal bool
+
+	// SyncAssignees carries over the source assignees that can be assigned in the target
+	SyncAssignees bool
+	// ExpandTeams assigns the members TeamMap lists for the teams mentioned
+	// in a source body, noting those that can not be assigned in the target
+	ExpandTeams bool
+	// TeamMap lists the member logins of source teams, keyed by org/team
+	TeamMap map[string][]string
+	// AssigneeFallback is assigned to migrated issues left without an assignee
+	AssigneeFallback string
+	// OnAssigneeError is AssigneeErrorDrop, AssigneeErrorSkip or
+	// AssigneeErrorFail and decides what happens when a synced assignee can
+	// not be assigned in the target
+	OnAssigneeError string
+
+	// TargetMilestone is assigned to every migrated issue, created if missing
+	TargetMilestone string
+	// SyncMilestones assigns each issue to the target milestone titled like
+	// its source milestone, created with the same state, due date and description
+	SyncMilestones bool
+	// TargetTemplate names a markdown issue template or issue form of the
+	// target whose structure is prepended to every migrated body
+	TargetTemplate string
+	// IncludeTypes carries the issue type over, or records it in the body
+	// when the target does not support types
+	IncludeTypes bool
+	// TypeMap renames source issue types in the target
+	TypeMap map[string]string
+	// CreateAsDraft creates target issues closed, labelled PendingReviewLabel
+	// and with a note, for a reviewer to reopen once verified
+	CreateAsDraft bool
+	// PendingReviewLabel marks target issues created by CreateAsDraft
+	PendingReviewLabel string
+	// ReplicateReactions adds one reaction of each kind the source issue
+	// received to the target issue, attributed to the token's user
+	ReplicateReactions bool
+	// PreservePins pins the target issues migrated from pinned source issues,
+	// as far as the target's pin limit allows
+	PreservePins bool
+	// SourceComment posts a comment on each target issue linking back to the source
+	SourceComment bool
+	// AddLabels are added to every target issue, created if missing, to tag
+	// the issues of a migration
+	AddLabels []string
+	// RequireLabel is added to every target issue that lacks it
+	RequireLabel string
+	// RequireAnyOf fails issues whose target labels include none of these
+	RequireAnyOf []string
+	// IncludeSubIssues re-establishes parent/sub-issue links between migrated
+	// issues once MigrateAll is done, noting links to unmigrated issues in the body
+	IncludeSubIssues bool
+	// IncludeDuplicates notes which issue a migrated issue was marked a
+	// duplicate of once MigrateAll is done, using its migrated copy if any
+	IncludeDuplicates bool
+	// IncludeResolution notes the pull requests that closed a source issue
+	IncludeResolution bool
+	// IncludeDevRefs notes the branches and pull requests linked to a source
+	// issue in its Development section
+	IncludeDevRefs bool
+	// IncludeLabelHistory notes the last LabelHistoryLimit times a label was
+	// added to or removed from a source issue
+	IncludeLabelHistory bool
+	// LabelHistoryLimit bounds the label events IncludeLabelHistory notes
+	LabelHistoryLimit int
+	// IncludeParticipants notes the reporter, assignees and commenters of a
+	// source issue, without mentioning them
+	IncludeParticipants bool
+	// CommentSince drops comments created before it from collation
+	CommentSince time.Time
+	// CommentAuthors limits collation to comments by these logins, when set
+	CommentAuthors []string
+	// CommentCollapseThreshold collapses the collated comments of issues with
+	// more comments than it into a details block, zero never collapses
+	CommentCollapseThreshold int
+	// CommentDedup drops comments duplicating an earlier one from collation
+	CommentDedup bool
+	// FirstCommentAsBody moves the first comment of issues with an empty or
+	// trivially short body into the body, instead of collating it
+	FirstCommentAsBody bool
+	// CombinedEdit edits title, body and comments in a single Edit call
+	CombinedEdit bool
+	// AutoClean migrates without prompting, leaving issues that need human
+	// judgment for manual review instead. A Prompter is not required.
+	AutoClean bool
+	// URLMap rewrites links to the hosts of its keys to the hosts of its
+	// values in bodies and comments. Links to hosts mapped to "" are replaced
+	// by InternalLinkPlaceholder.
+	URLMap map[string]string
+	// OnPrivateAsset is AssetActionWarn or AssetActionPlaceholder and decides
+	// what happens to attachments of a private source in bodies and comments
+	OnPrivateAsset string
+	// RewriteInternalLinks replaces the other links holding Blocklist terms
+	// by InternalLinkPlaceholder
+	RewriteInternalLinks bool
+	// Transform, when set, rewrites the source body (kind "body") and each
+	// comment (kind "comment") before review. An error fails the issue.
+	Transform func(kind, content string) (string, error)
+	// CommentEditAll edits every collated comment on its own before it is added
+	CommentEditAll bool
+	// CommentMaxSize is the most characters a collated comment may have
+	// before OnLargeComment applies, 0 for no limit
+	CommentMaxSize int
+	// OnLargeComment is LargeCommentCollapse or LargeCommentTruncate
+	OnLargeComment string
+	// BodyPreamble is a text/template prepended to every migrated body once
+	// it is reviewed, rendered from the source issue's .Number, .Title, .URL,
+	// .Author, .CreatedAt, .State and .Repo
+	BodyPreamble string
+	// CommentStyle is CommentStyleBlock or CommentStyleInline and picks the
+	// CommentAttribution used when it is unset
+	CommentStyle string
+	// CommentAttribution is a text/template rendering each collated comment
+	// from its .Author, .CreatedAt, .URL and .Body
+	CommentAttribution string
+	// MaxBodyLength is the most characters a migrated body may have
+	MaxBodyLength int
+	// Overflow is OverflowTruncate or OverflowComments and decides what
+	// happens to the collated comments past MaxBodyLength
+	Overflow string
+	// Emoji is EmojiKeep or EmojiStrip, strip removes :shortcode: emoji
+	Emoji string
+	// Browse offers to open each source issue in the browser
+	Browse bool
+
+	// UseGraphQL fetches the comments of all source issues with a paginated
+	// GraphQL query, falling back to REST on any GraphQL error
+	UseGraphQL bool
+
+	// MarkdownDir receives a markdown file per migrated issue when set
+	MarkdownDir string
+	// NoTarget only writes issues to MarkdownDir, nothing is created in or
+	// read from the target and the source is left untouched
+	NoTarget bool
+
+	// Prompter reviews each issue, it is required
+	Prompter Prompter
+	// MaxAPICalls stops MigrateAll with an APIBudgetError before the next
+	// issue once this many API calls were sent, 0 for no limit. The issue in
+	// progress is finished, so the budget can be exceeded by its calls.
+	MaxAPICalls int64
+	// Out receives progress messages, nil discards them
+	Out io.Writer
+	// Color highlights warnings in progress messages with ANSI colors
+	Color bool
+	// OnResult is called with the result of each source issue as it completes
+	OnResult func(Result)
+	// OnEvent is called as each source issue is started, created in the
+	// target and done, for embedding applications that render progress
+	OnEvent func(Event)
+}
+
+func (c *Config) setDefaults() {
+	if c.MigratedToLabel == "" {
+		c.MigratedToLabel = DefaultMigratedToLabel
+	}
+	if c.MigratedFromLabel == "" {
+		c.MigratedFromLabel = DefaultMigratedFromLabel
+	}
+	if c.SkipLabel == "" {
+		c.SkipLabel = DefaultSkipLabel
+	}
+	if c.SecurityLabel == "" {
+		c.SecurityLabel = DefaultSecurityLabel
+	}
+	if c.BannedLabels == nil {
+		c.BannedLabels = DefaultBannedLabels
+	}
+	if c.Blocklist == nil {
+		c.Blocklist = DefaultBlocklist
+	}
+	if c.LabelHistoryLimit == 0 {
+		c.LabelHistoryLimit = DefaultLabelHistoryLimit
+	}
+	if c.OnPrivateAsset == "" {
+		c.OnPrivateAsset = AssetActionWarn
+	}
+	if c.CodeBlockPolicy == "" {
+		c.CodeBlockPolicy = CodeBlockScan
+	}
+	if c.OnLabelCollision == "" {
+		c.OnLabelCollision = LabelCollisionReuse
+	}
+	if c.OnLabelError == "" {
+		c.OnLabelError = LabelErrorWarn
+	}
+	if c.OnInvalidLabel == "" {
+		c.OnInvalidLabel = InvalidLabelSanitize
+	}
+	if c.OnAssigneeError == "" {
+		c.OnAssigneeError = AssigneeErrorDrop
+	}
+	if c.PendingReviewLabel == "" {
+		c.PendingReviewLabel = DefaultPendingReviewLabel
+	}
+	if c.UserAgent == "" {
+		c.UserAgent = DefaultUserAgent
+	}
+	if c.OnLargeComment == "" {
+		c.OnLargeComment = LargeCommentCollapse
+	}
+	if c.CommentStyle == "" {
+		c.CommentStyle = CommentStyleBlock
+	}
+	if c.CommentAttribution == "" && c.CommentStyle == CommentStyleInline {
+		c.CommentAttribution = InlineCommentAttribution
+	}
+	if c.CommentAttribution == "" {
+		c.CommentAttribution = DefaultCommentAttribution
+	}
+	if c.MaxBodyLength == 0 {
+		c.MaxBodyLength = DefaultMaxBodyLength
+	}
+	if c.Overflow == "" {
+		c.Overflow = OverflowTruncate
+	}
+	if c.Emoji == "" {
+		c.Emoji = EmojiKeep
+	}
+	if c.Out == nil {
+		c.Out = io.Discard
+	}
+}
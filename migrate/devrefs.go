@@ -0,0 +1,59 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// developmentRefs returns the branches and pull requests linked to a source
+// issue in its Development section. Branches are rendered as plain text,
+// since they may not exist wherever the migrated issue is read.
+func (m *Migrator) developmentRefs(ctx context.Context, issue *github.Issue) ([]string, error) {
+	var data struct {
+		Node *struct {
+			LinkedBranches struct {
+				Nodes []struct {
+					Ref *struct {
+						Name       string `json:"name"`
+						Repository struct {
+							NameWithOwner string `json:"nameWithOwner"`
+						} `json:"repository"`
+					} `json:"ref"`
+				} `json:"nodes"`
+			} `json:"linkedBranches"`
+			ClosedByPullRequestsReferences struct {
+				Nodes []struct {
+					URL string `json:"url"`
+				} `json:"nodes"`
+			} `json:"closedByPullRequestsReferences"`
+		} `json:"node"`
+	}
+	err := m.graphql(ctx, m.source, `query($id: ID!) {
+  node(id: $id) {
+    ... on Issue {
+      linkedBranches(first: 50) { nodes { ref { name repository { nameWithOwner } } } }
+      closedByPullRequestsReferences(first: 50, includeClosedPrs: true) { nodes { url } }
+    }
+  }
+}`, map[string]interface{}{"id": issue.GetNodeID()}, &data)
+	if err != nil {
+		return nil, newAPIError("get development refs", err)
+	}
+	if data.Node == nil {
+		return nil, nil
+	}
+
+	var refs []string
+	for _, b := range data.Node.LinkedBranches.Nodes {
+		// the ref is null once the branch is deleted
+		if b.Ref == nil {
+			continue
+		}
+		refs = append(refs, "`"+b.Ref.Repository.NameWithOwner+"@"+b.Ref.Name+"`")
+	}
+	for _, pr := range data.Node.ClosedByPullRequestsReferences.Nodes {
+		refs = append(refs, pr.URL)
+	}
+	return refs, nil
+}
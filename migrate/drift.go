@@ -0,0 +1,187 @@
+package migrate
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// Drift describes how a migrated issue differs from its source issue
+type Drift struct {
+	Source    int    `json:"source"`
+	SourceURL string `json:"source_url"`
+	Dest      int    `json:"dest"`
+	DestURL   string `json:"dest_url"`
+	// Title and Body report which of them differ
+	Title bool `json:"title"`
+	Body  bool `json:"body"`
+	// Applied is set once the target issue was re-synced
+	Applied bool `json:"applied"`
+	// Blocked holds the internal term that kept the source from being re-synced
+	Blocked string `json:"blocked,omitempty"`
+	// Refused explains why a target issue could not be re-synced safely
+	Refused string `json:"refused,omitempty"`
+}
+
+// migratedSuffixes start the parts migratron appends to a migrated body. They
+// only split the bodies of markers written before drift tracking.
+var migratedSuffixes = []string{
+	"\n### Collated Context",
+	"\n\nOriginally resolved by",
+	"\n\nOriginal development refs:",
+	"\n\nOriginal participants:",
+	"\n\nType: ",
+	"<!-- migratron:source=",
+}
+
+// splitMigratedBody splits a migrated body into the source body it was
+// created from and what migratron appended to it
+func splitMigratedBody(body string) (source, appended string) {
+	cut := len(body)
+	for _, s := range migratedSuffixes {
+		if i := strings.Index(body, s); i >= 0 && i < cut {
+			cut = i
+		}
+	}
+	for cut > 0 && body[cut-1] == '\n' {
+		cut--
+	}
+	return body[:cut], body[cut:]
+}
+
+func sameText(a, b string) bool {
+	norm := func(s string) string {
+		return strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+	}
+	return norm(a) == norm(b)
+}
+
+// Drift compares every migrated target issue, found by its provenance
+// marker, with its source issue. With apply, drifted target issues get the
+// current source title and body, prepared as for a migration and keeping
+// what migratron added around them. Issues a reviewer edited, or migrated
+// before drift tracking, are refused, and so are sources that would carry
+// internal terms.
+func (m *Migrator) Drift(ctx context.Context, apply bool) ([]Drift, error) {
+	var err error
+	if m.blocklist, err = compileBlocklist(m.cfg.Blocklist); err != nil {
+		return nil, err
+	}
+	if apply {
+		if err := m.validateContent(); err != nil {
+			return nil, err
+		}
+		// learns whether the source is private, for the private asset policy
+		if err := m.preflight(ctx); err != nil {
+			return nil, err
+		}
+	}
+	migrated, err := m.indexMigrated(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sources := make([]int, 0, len(migrated))
+	for n := range migrated {
+		sources = append(sources, n)
+	}
+	sort.Ints(sources)
+
+	from, to := m.cfg.From, m.cfg.To
+	var drifts []Drift
+	for _, n := range sources {
+		target := migrated[n]
+		source, _, err := m.source.Issues.Get(ctx, from.Owner, from.Name, n)
+		if err != nil {
+			return drifts, newAPIError("get issue", err)
+		}
+		p, _ := parseProvenance(target.GetBody())
+		d := Drift{
+			Source:    n,
+			SourceURL: source.GetHTMLURL(),
+			Dest:      target.GetNumber(),
+			DestURL:   target.GetHTMLURL(),
+		}
+		if p.Body != "" {
+			d.Title = hashText(source.GetTitle()) != p.Title
+			d.Body = hashText(source.GetBody()) != p.Body
+		} else {
+			body, _ := splitMigratedBody(target.GetBody())
+			d.Title = !sameText(source.GetTitle(), target.GetTitle())
+			d.Body = !sameText(source.GetBody(), body)
+		}
+		if !d.Title && !d.Body {
+			continue
+		}
+		if apply {
+			req, err := m.resync(ctx, source, target, p, &d)
+			if err != nil {
+				return drifts, err
+			}
+			if req != nil {
+				err = m.withSecondaryRetry(ctx, func() (err error) {
+					_, _, err = m.client.Issues.Edit(ctx, to.Owner, to.Name, target.GetNumber(), req)
+					return err
+				})
+				if err != nil {
+					return drifts, newAPIError("edit issue", err)
+				}
+				d.Applied = true
+			}
+		}
+		drifts = append(drifts, d)
+	}
+	return drifts, nil
+}
+
+// resync builds the edit re-syncing a drifted target issue from its source.
+// It returns nil and sets Blocked or Refused on d when the issue must be left
+// alone. The source body goes through the same preparation as a migration
+// and replaces only the part of the target body it was synced into.
+func (m *Migrator) resync(ctx context.Context, source, target *github.Issue, p provenance, d *Drift) (*github.IssueRequest, error) {
+	if p.Body == "" {
+		d.Refused = "it was migrated before drift was tracked"
+		return nil, nil
+	}
+	next := p
+	next.Title, next.Body = hashText(source.GetTitle()), hashText(source.GetBody())
+
+	title := target.GetTitle()
+	if d.Title {
+		if hashText(title) != p.Title {
+			d.Refused = "its title was edited after migration"
+			return nil, nil
+		}
+		var err error
+		if title, err = m.normalizeText(ctx, source.GetTitle()); err != nil {
+			return nil, err
+		}
+	}
+
+	body := target.GetBody()
+	synced := ""
+	if d.Body {
+		if p.Synced == "" || p.End > len(body) || hashText(body[p.Start:p.End]) != p.Synced {
+			d.Refused = "its body was edited in review or after migration"
+			return nil, nil
+		}
+		prepared, _, _, err := m.prepareContent(source, nil)
+		if err != nil {
+			d.Refused = "the source failed its transform: " + err.Error()
+			return nil, nil
+		}
+		if synced, err = m.normalizeText(ctx, prepared.GetBody()); err != nil {
+			return nil, err
+		}
+		body = body[:p.Start] + synced + body[p.End:]
+		next.End, next.Synced = p.Start+len(synced), hashText(synced)
+	}
+
+	if term := m.internalTerm(title + "\n" + synced); term != "" {
+		d.Blocked = term
+		return nil, nil
+	}
+	body = provenanceRe.ReplaceAllLiteralString(body, next.marker())
+	return &github.IssueRequest{Title: &title, Body: &body}, nil
+}
@@ -0,0 +1,72 @@
+package migrate
+
+import (
+	"context"
+	"strings"
+)
+
+// sourceDuplicateOf returns the issue a source issue was last marked as a
+// duplicate of, or nil if it was not or was unmarked since
+func (m *Migrator) sourceDuplicateOf(ctx context.Context, number int) (*relatedIssue, error) {
+	var data struct {
+		Repository struct {
+			Issue struct {
+				TimelineItems struct {
+					Nodes []struct {
+						Typename  string        `json:"__typename"`
+						Canonical *relatedIssue `json:"canonical"`
+					} `json:"nodes"`
+				} `json:"timelineItems"`
+			} `json:"issue"`
+		} `json:"repository"`
+	}
+	err := m.graphql(ctx, m.source, `query($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) {
+    issue(number: $number) {
+      timelineItems(itemTypes: [MARKED_AS_DUPLICATE_EVENT, UNMARKED_AS_DUPLICATE_EVENT], last: 1) {
+        nodes { __typename ... on MarkedAsDuplicateEvent { canonical { ... on Issue { number url repository { nameWithOwner } } } } }
+      }
+    }
+  }
+}`, map[string]interface{}{"owner": m.cfg.From.Owner, "name": m.cfg.From.Name, "number": number}, &data)
+	if err != nil {
+		return nil, newAPIError("get duplicate", err)
+	}
+	nodes := data.Repository.Issue.TimelineItems.Nodes
+	if len(nodes) == 0 || nodes[0].Typename != "MarkedAsDuplicateEvent" {
+		return nil, nil
+	}
+	return nodes[0].Canonical, nil
+}
+
+// noteDuplicates records in the target body of each issue migrated in this
+// run which issue it duplicates, pointing at the migrated canonical issue
+// when there is one
+func (m *Migrator) noteDuplicates(ctx context.Context, results []Result) error {
+	targets := m.migratedTargets(results)
+	for _, res := range results {
+		if res.Status != StatusMigrated {
+			continue
+		}
+		canonical, err := m.sourceDuplicateOf(ctx, res.Source)
+		if err != nil {
+			return err
+		}
+		// canonical pull requests are left out by the query
+		if canonical == nil || canonical.URL == "" {
+			continue
+		}
+		// a URL rather than #N, which issues relink would take for a source
+		// issue number
+		ref := canonical.URL
+		if strings.EqualFold(canonical.Repository.NameWithOwner, m.cfg.From.String()) {
+			if t, ok := targets[canonical.Number]; ok && t.DestURL != "" {
+				ref = t.DestURL
+			}
+		}
+		if err := m.appendToBody(ctx, res.Dest, "Duplicate of "+ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v36/github"
+)
+
+func TestNoteDuplicatesSurvivesRelink(t *testing.T) {
+	f := newFakeGitHub(t)
+	// the target already holds an unrelated issue, so target numbers are one
+	// past the source numbers they were migrated from
+	f.addIssue(testTarget, &github.Issue{Title: github.String("Unrelated")})
+	canonical := f.addIssue(testTarget, &github.Issue{Title: github.String("Crash on start")})
+	duplicate := f.addIssue(testTarget, &github.Issue{Title: github.String("Crashes at startup")})
+	f.graphql = func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables struct {
+				Number int `json:"number"`
+			} `json:"variables"`
+		}
+		readJSON(r, &req)
+		var nodes []interface{}
+		if req.Variables.Number == 2 {
+			nodes = append(nodes, map[string]interface{}{
+				"__typename": "MarkedAsDuplicateEvent",
+				"canonical": map[string]interface{}{
+					"number":     1,
+					"url":        fmt.Sprintf("https://github.com/%s/issues/1", testSource),
+					"repository": map[string]string{"nameWithOwner": testSource.String()},
+				},
+			})
+		}
+		writeJSON(w, map[string]interface{}{"data": map[string]interface{}{
+			"repository": map[string]interface{}{"issue": map[string]interface{}{"timelineItems": map[string]interface{}{"nodes": nodes}}},
+		}})
+	}
+	results := []Result{
+		{Source: 1, Status: StatusMigrated, Dest: canonical.GetNumber(), DestURL: canonical.GetHTMLURL()},
+		{Source: 2, Status: StatusMigrated, Dest: duplicate.GetNumber(), DestURL: duplicate.GetHTMLURL()},
+	}
+
+	if err := newTestMigrator(t, f, Config{}).noteDuplicates(context.Background(), results); err != nil {
+		t.Fatal(err)
+	}
+	body := duplicate.GetBody()
+	if want := "Duplicate of " + canonical.GetHTMLURL(); !strings.Contains(body, want) {
+		t.Fatalf("duplicate has body %q, want it to note %q", body, want)
+	}
+	byNumber := map[int]Result{}
+	for _, res := range results {
+		byNumber[res.Source] = res
+	}
+	if relinked := rewriteRefs(body, testSource, byNumber); relinked != body {
+		t.Errorf("relink changed the duplicate note from %q to %q", body, relinked)
+	}
+}
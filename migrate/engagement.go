@@ -0,0 +1,19 @@
+package migrate
+
+import (
+	"fmt"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// lowEngagement explains why issue falls below the MinComments or
+// MinReactions threshold of opts, or returns "" if it meets both
+func lowEngagement(issue *github.Issue, opts AllOptions) string {
+	if n := issue.GetComments(); n < opts.MinComments {
+		return fmt.Sprintf("%d comments, fewer than %d", n, opts.MinComments)
+	}
+	if n := issue.GetReactions().GetTotalCount(); n < opts.MinReactions {
+		return fmt.Sprintf("%d reactions, fewer than %d", n, opts.MinReactions)
+	}
+	return ""
+}
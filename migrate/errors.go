@@ -0,0 +1,93 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// Configuration errors, returned before anything is read from or written to GitHub
+var (
+	ErrMissingLogin      = errors.New("login must be set")
+	ErrBadRepoFormat     = errors.New("repo is not in org/repo format")
+	ErrInvalidConfig     = errors.New("invalid configuration")
+	ErrTargetNotEmpty    = errors.New("target repo already has issues")
+	ErrIssuesDisabled    = errors.New("issues are disabled")
+	ErrInsufficientScope = errors.New("token lacks a required scope")
+)
+
+// Errors describing why an issue can not be migrated
+var (
+	ErrIsPullRequest = errors.New("this is a PR, can not migrate")
+	ErrSkipLabel     = errors.New("issue has the skip label applied")
+	ErrSecurityIssue = errors.New("issue holds security sensitive details")
+	ErrUnassignable  = errors.New("issue has assignees that can not be assigned")
+	ErrInvalidLabel  = errors.New("label name is rejected by GitHub")
+	// ErrInternalContent is matched by InternalContentError
+	ErrInternalContent = errors.New("internal terms found")
+)
+
+// ErrBadCombinedEdit is returned when a combined edit document can not be split back into its sections
+var ErrBadCombinedEdit = errors.New("combined edit is missing a section delimiter")
+
+// ErrUserAborted is returned by a Prompter when the user interrupts the migration
+var ErrUserAborted = errors.New("aborted by user")
+
+// ErrAPIBudget is matched by APIBudgetError
+var ErrAPIBudget = errors.New("API call budget reached")
+
+// APIBudgetError is returned by MigrateAll when MaxAPICalls is reached
+// before every issue was processed. Next is the source issue to resume from.
+type APIBudgetError struct {
+	Calls int64
+	Next  int
+}
+
+func (e *APIBudgetError) Error() string {
+	return fmt.Sprintf("%v after %d calls, issue %d is next", ErrAPIBudget, e.Calls, e.Next)
+}
+
+func (e *APIBudgetError) Unwrap() error {
+	return ErrAPIBudget
+}
+
+// APIError wraps a failed GitHub API call with the operation that was attempted
+type APIError struct {
+	Op  string
+	Err error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Op, e.Err)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the HTTP status of the failed call, or 0 if there was no response
+func (e *APIError) StatusCode() int {
+	var errResp *github.ErrorResponse
+	if errors.As(e.Err, &errResp) && errResp.Response != nil {
+		return errResp.Response.StatusCode
+	}
+	return 0
+}
+
+// InteractionLimited reports whether the call was refused by the interaction
+// limits of a repo or org, rather than by the token's permissions
+func (e *APIError) InteractionLimited() bool {
+	var errResp *github.ErrorResponse
+	if !errors.As(e.Err, &errResp) || e.StatusCode() != http.StatusForbidden {
+		return false
+	}
+	msg := strings.ToLower(errResp.Message + " " + errResp.DocumentationURL)
+	return strings.Contains(msg, "limited the ability") || strings.Contains(msg, "interaction")
+}
+
+func newAPIError(op string, err error) error {
+	return &APIError{Op: op, Err: err}
+}
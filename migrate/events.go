@@ -0,0 +1,28 @@
+package migrate
+
+// EventKind is the step of a source issue an Event reports
+type EventKind string
+
+const (
+	// EventStarted is sent before a source issue is reviewed
+	EventStarted EventKind = "started"
+	// EventCreated is sent once the target issue exists, before comments,
+	// labels and the source are updated
+	EventCreated EventKind = "created"
+	// EventDone is sent with the final Result of a source issue, including
+	// issues skipped without review and failures
+	EventDone EventKind = "done"
+)
+
+// Event reports the progress of one source issue. Result holds the source
+// for every kind, the target once created and the Status and Error when done.
+type Event struct {
+	Kind   EventKind `json:"kind"`
+	Result Result    `json:"result"`
+}
+
+func (m *Migrator) emit(kind EventKind, res Result) {
+	if m.cfg.OnEvent != nil {
+		m.cfg.OnEvent(Event{Kind: kind, Result: res})
+	}
+}
@@ -0,0 +1,147 @@
+package migrate
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// ExportSchemaVersion is the version of the export line format written by
+// this package, ReadExport rejects any other version
+const ExportSchemaVersion = 1
+
+// ErrBadExport is returned by ReadExport for lines that do not match the export schema
+var ErrBadExport = errors.New("invalid export")
+
+// ExportedComment is a comment of an ExportedIssue
+type ExportedComment struct {
+	User      string    `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+	Body      string    `json:"body"`
+}
+
+// ExportedIssue is one line of an export file
+type ExportedIssue struct {
+	SchemaVersion int               `json:"schema_version"`
+	Number        int               `json:"number"`
+	URL           string            `json:"url"`
+	State         string            `json:"state"`
+	Title         string            `json:"title"`
+	Body          string            `json:"body"`
+	Labels        []string          `json:"labels"`
+	CreatedAt     time.Time         `json:"created_at"`
+	ClosedAt      time.Time         `json:"closed_at"`
+	Comments      []ExportedComment `json:"comments"`
+}
+
+// required fields of each export line and comment
+var (
+	exportRequired        = []string{"schema_version", "number", "url", "state", "title", "created_at"}
+	exportCommentRequired = []string{"user", "created_at", "body"}
+)
+
+// ReadExport reads and validates an export file, one issue per line
+func ReadExport(r io.Reader) ([]ExportedIssue, error) {
+	var issues []ExportedIssue
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		e, err := parseExportLine(scanner.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrBadExport, line, err)
+		}
+		issues = append(issues, e)
+	}
+	return issues, scanner.Err()
+}
+
+func parseExportLine(b []byte) (ExportedIssue, error) {
+	var e ExportedIssue
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return e, err
+	}
+	if err := requireFields(raw, exportRequired); err != nil {
+		return e, err
+	}
+	var version int
+	if err := json.Unmarshal(raw["schema_version"], &version); err != nil || version != ExportSchemaVersion {
+		return e, fmt.Errorf("schema_version %s is not supported, expected %d", raw["schema_version"], ExportSchemaVersion)
+	}
+	if c, ok := raw["comments"]; ok && string(c) != "null" {
+		var comments []map[string]json.RawMessage
+		if err := json.Unmarshal(c, &comments); err != nil {
+			return e, fmt.Errorf("field \"comments\": expected an array of objects")
+		}
+		for i, comment := range comments {
+			if err := requireFields(comment, exportCommentRequired); err != nil {
+				return e, fmt.Errorf("comment %d: %v", i, err)
+			}
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&e); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return e, fmt.Errorf("field %q: expected %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+		}
+		return e, err
+	}
+	if e.State != "open" && e.State != "closed" {
+		return e, fmt.Errorf("field \"state\": expected open or closed, got %q", e.State)
+	}
+	return e, nil
+}
+
+func requireFields(raw map[string]json.RawMessage, fields []string) error {
+	for _, f := range fields {
+		if _, ok := raw[f]; !ok {
+			return fmt.Errorf("missing required field %q", f)
+		}
+	}
+	return nil
+}
+
+// exportIssue writes a snapshot of the issue and its comments to w as a single JSON line
+func (m *Migrator) exportIssue(ctx context.Context, w io.Writer, issue *github.Issue) error {
+	c, err := m.issueComments(ctx, *issue.Number)
+	if err != nil {
+		return err
+	}
+	e := ExportedIssue{
+		SchemaVersion: ExportSchemaVersion,
+		Number:        issue.GetNumber(),
+		URL:           issue.GetHTMLURL(),
+		State:         issue.GetState(),
+		Title:         issue.GetTitle(),
+		Body:          issue.GetBody(),
+		CreatedAt:     issue.GetCreatedAt(),
+		ClosedAt:      issue.GetClosedAt(),
+	}
+	for _, l := range issue.Labels {
+		e.Labels = append(e.Labels, l.GetName())
+	}
+	for _, comment := range c {
+		e.Comments = append(e.Comments, ExportedComment{
+			User:      comment.GetUser().GetLogin(),
+			CreatedAt: comment.GetCreatedAt(),
+			Body:      comment.GetBody(),
+		})
+	}
+
+	return json.NewEncoder(w).Encode(e)
+}
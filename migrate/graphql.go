@@ -0,0 +1,54 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// graphqlError is an error reported in the errors field of a GraphQL response
+type graphqlError struct {
+	Message string `json:"message"`
+}
+
+// graphql runs query against the GraphQL API of client and decodes its data into v.
+// features are sent in the GraphQL-Features header to opt into previews.
+func (m *Migrator) graphql(ctx context.Context, client *github.Client, query string, vars map[string]interface{}, v interface{}, features ...string) error {
+	// GitHub Enterprise Server serves GraphQL at /api/graphql, beside /api/v3/
+	endpoint := "graphql"
+	if strings.HasSuffix(client.BaseURL.Path, "/v3/") {
+		endpoint = "../graphql"
+	}
+	req, err := client.NewRequest("POST", endpoint, map[string]interface{}{
+		"query":     query,
+		"variables": vars,
+	})
+	if err != nil {
+		return err
+	}
+	if len(features) > 0 {
+		req.Header.Set("GraphQL-Features", strings.Join(features, ","))
+	}
+
+	var out struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphqlError  `json:"errors"`
+	}
+	if _, err := client.Do(ctx, req, &out); err != nil {
+		return err
+	}
+	if len(out.Errors) > 0 {
+		msgs := make([]string, len(out.Errors))
+		for i, e := range out.Errors {
+			msgs[i] = e.Message
+		}
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(out.Data, v)
+}
@@ -0,0 +1,234 @@
+package migrate
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// InternalFinding locates a blocklist term found in a source issue
+type InternalFinding struct {
+	Issue    int    `json:"issue"`
+	URL      string `json:"url"`
+	Location string `json:"location"`
+	Term     string `json:"term"`
+}
+
+func (f InternalFinding) String() string {
+	return fmt.Sprintf("%s %s: %q", f.URL, f.Location, f.Term)
+}
+
+// InternalContentError is returned when FailOnInternal is set and internal
+// terms were found. It matches ErrInternalContent with errors.Is.
+type InternalContentError struct {
+	Findings []InternalFinding
+}
+
+func (e *InternalContentError) Error() string {
+	lines := make([]string, len(e.Findings))
+	for i, f := range e.Findings {
+		lines[i] = "  " + f.String()
+	}
+	return fmt.Sprintf("%v in %d places:\n%s", ErrInternalContent, len(e.Findings), strings.Join(lines, "\n"))
+}
+
+func (e *InternalContentError) Unwrap() error {
+	return ErrInternalContent
+}
+
+// blocklistRegexPrefix marks a Blocklist entry as a regular expression
+const blocklistRegexPrefix = "re:"
+
+// blockTerm is a compiled Blocklist entry, matching either literally or by re
+type blockTerm struct {
+	term string
+	re   *regexp.Regexp
+}
+
+// compileBlocklist compiles the regular expressions of a Blocklist
+func compileBlocklist(blocklist []string) ([]blockTerm, error) {
+	terms := make([]blockTerm, len(blocklist))
+	for i, b := range blocklist {
+		terms[i].term = b
+		if !strings.HasPrefix(b, blocklistRegexPrefix) {
+			continue
+		}
+		re, err := regexp.Compile(strings.TrimPrefix(b, blocklistRegexPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("%w: blocklist pattern %q: %v", ErrInvalidConfig, b, err)
+		}
+		terms[i].re = re
+	}
+	return terms, nil
+}
+
+// ReadBlocklist reads Blocklist entries one per line, skipping blank lines
+// and # comments. Entries prefixed with re: are regular expressions.
+func ReadBlocklist(r io.Reader) ([]string, error) {
+	var blocklist []string
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if _, err := compileBlocklist([]string{entry}); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		blocklist = append(blocklist, entry)
+	}
+	return blocklist, scanner.Err()
+}
+
+// internalTerm returns the first blocklist term s contains, or "". For
+// regular expressions it returns the matched text. Fenced code blocks are
+// matched according to CodeBlockPolicy.
+func (m *Migrator) internalTerm(s string) string {
+	if m.cfg.CodeBlockPolicy == CodeBlockScan {
+		return m.matchTerm(s, false)
+	}
+	prose, code, _ := splitFenced(s)
+	if term := m.matchTerm(prose, false); term != "" {
+		return term
+	}
+	if m.cfg.CodeBlockPolicy == CodeBlockStrict {
+		return m.matchTerm(code, true)
+	}
+	return ""
+}
+
+// matchTerm returns the first blocklist term s contains, or "". With fold
+// set literal terms are matched regardless of case.
+func (m *Migrator) matchTerm(s string, fold bool) string {
+	lower := strings.ToLower(s)
+	for _, b := range m.blocklist {
+		if b.re != nil {
+			if match := b.re.FindString(s); match != "" {
+				return match
+			}
+		} else if strings.Contains(s, b.term) || fold && strings.Contains(lower, strings.ToLower(b.term)) {
+			return b.term
+		}
+	}
+	return ""
+}
+
+// internalCount returns how often blocklist terms occur in s, matched like
+// internalTerm
+func (m *Migrator) internalCount(s string) int {
+	if m.cfg.CodeBlockPolicy == CodeBlockScan {
+		return m.countTerms(s, false)
+	}
+	prose, code, _ := splitFenced(s)
+	n := m.countTerms(prose, false)
+	if m.cfg.CodeBlockPolicy == CodeBlockStrict {
+		n += m.countTerms(code, true)
+	}
+	return n
+}
+
+// countTerms returns how often blocklist terms occur in s. With fold set
+// literal terms are counted regardless of case.
+func (m *Migrator) countTerms(s string, fold bool) int {
+	lower := strings.ToLower(s)
+	n := 0
+	for _, b := range m.blocklist {
+		switch {
+		case b.re != nil:
+			n += len(b.re.FindAllStringIndex(s, -1))
+		case fold:
+			n += strings.Count(lower, strings.ToLower(b.term))
+		default:
+			n += strings.Count(s, b.term)
+		}
+	}
+	return n
+}
+
+// internalSummary counts the blocklist hits of an issue by location, like
+// "2 internal refs in body, 1 in comments", or "" when there are none
+func (m *Migrator) internalSummary(issue *github.Issue, comments []*github.IssueComment) string {
+	counts := []struct {
+		location string
+		n        int
+	}{
+		{"title", m.internalCount(issue.GetTitle())},
+		{"body", m.internalCount(issue.GetBody())},
+		{"comments", 0},
+	}
+	for _, c := range comments {
+		counts[2].n += m.internalCount(c.GetBody())
+	}
+	var parts []string
+	for _, c := range counts {
+		if c.n == 0 {
+			continue
+		}
+		if len(parts) == 0 {
+			refs := "refs"
+			if c.n == 1 {
+				refs = "ref"
+			}
+			parts = append(parts, fmt.Sprintf("%d internal %s in %s", c.n, refs, c.location))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d in %s", c.n, c.location))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// scanIssues checks the title, body and comments of every issue for
+// blocklist terms before anything is created
+func (m *Migrator) scanIssues(ctx context.Context, issues []*github.Issue) error {
+	var findings []InternalFinding
+	for _, i := range issues {
+		add := func(location, s string) {
+			if term := m.internalTerm(s); term != "" {
+				findings = append(findings, InternalFinding{Issue: i.GetNumber(), URL: i.GetHTMLURL(), Location: location, Term: term})
+			}
+		}
+		add("title", i.GetTitle())
+		add("body", i.GetBody())
+
+		comments, err := m.issueComments(ctx, i.GetNumber())
+		if err != nil {
+			return err
+		}
+		comments, _ = commentsSince(comments, m.cfg.CommentSince)
+		comments, _ = commentsByAuthors(comments, m.cfg.CommentAuthors)
+		for _, c := range comments {
+			add(fmt.Sprintf("comment %d", c.GetID()), c.GetBody())
+		}
+	}
+	if len(findings) > 0 {
+		return &InternalContentError{Findings: findings}
+	}
+	return nil
+}
+
+// willCreate reports whether MigrateAll would offer issue for creation in the target
+func (m *Migrator) willCreate(issue *github.Issue, opts AllOptions) bool {
+	if issue.IsPullRequest() || issue.GetCreatedAt().Before(opts.Since) {
+		return false
+	}
+	for _, l := range issue.Labels {
+		if l.GetName() == m.cfg.SkipLabel || l.GetName() == m.cfg.MigratedToLabel {
+			return false
+		}
+	}
+	if lowEngagement(issue, opts) != "" {
+		return false
+	}
+	if opts.Export != nil && issue.GetState() == "closed" {
+		return false
+	}
+	_, migrated := m.migrated[issue.GetNumber()]
+	return !migrated
+}
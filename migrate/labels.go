@@ -0,0 +1,428 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// Policies for Config.OnLabelError
+const (
+	LabelErrorSkip = "skip"
+	LabelErrorWarn = "warn"
+	LabelErrorFail = "fail"
+)
+
+// Policies for Config.OnLabelCollision
+const (
+	LabelCollisionReuse  = "reuse"
+	LabelCollisionRename = "rename"
+	LabelCollisionUpdate = "update"
+)
+
+// Policies for Config.OnInvalidLabel
+const (
+	InvalidLabelSkip     = "skip"
+	InvalidLabelFail     = "fail"
+	InvalidLabelSanitize = "sanitize"
+)
+
+// maxLabelLength is the most characters GitHub accepts in a label name
+const maxLabelLength = 50
+
+// labelProblem describes why GitHub would reject name, or returns ""
+func labelProblem(name string) string {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return "is empty"
+	case strings.TrimSpace(name) != name:
+		return "has leading or trailing space"
+	case utf8.RuneCountInString(name) > maxLabelLength:
+		return fmt.Sprintf("is longer than %d characters", maxLabelLength)
+	}
+	return ""
+}
+
+// fixLabel applies the OnInvalidLabel policy to name. It returns the name to
+// apply, "" to drop it, and the problem with name if GitHub would reject it.
+func (m *Migrator) fixLabel(name string) (fixed, problem string) {
+	if problem = labelProblem(name); problem == "" {
+		return name, ""
+	}
+	if m.cfg.OnInvalidLabel != InvalidLabelSanitize {
+		return "", problem
+	}
+	fixed = strings.TrimSpace(name)
+	if r := []rune(fixed); len(r) > maxLabelLength {
+		fixed = strings.TrimSpace(string(r[:maxLabelLength]))
+	}
+	if labelProblem(fixed) != "" {
+		return "", problem
+	}
+	return fixed, problem
+}
+
+// validateInvalidLabel checks policy is an OnInvalidLabel policy
+func validateInvalidLabel(policy string) error {
+	switch policy {
+	case InvalidLabelSkip, InvalidLabelFail, InvalidLabelSanitize:
+		return nil
+	}
+	return fmt.Errorf("%w: invalid label policy must be %s, %s or %s, got %q", ErrInvalidConfig, InvalidLabelSkip, InvalidLabelFail, InvalidLabelSanitize, policy)
+}
+
+// collisionSuffix is appended to the names of labels renamed on collision
+const collisionSuffix = "-migrated"
+
+// defaultLabelColor is used for created labels without a source label
+const defaultLabelColor = "ededed"
+
+var labelColorRe = regexp.MustCompile(`^#?[0-9a-fA-F]{6}$`)
+
+// validateLabelColors checks every LabelColors value is a hex color
+func validateLabelColors(colors map[string]string) error {
+	for name, color := range colors {
+		if !labelColorRe.MatchString(color) {
+			return fmt.Errorf("%w: label color %q for %q is not a hex color like d73a4a", ErrInvalidConfig, color, name)
+		}
+	}
+	return nil
+}
+
+// labelColor returns the LabelColors color for the target label name, or ""
+func (m *Migrator) labelColor(name string) string {
+	for n, color := range m.cfg.LabelColors {
+		if strings.EqualFold(n, name) {
+			return strings.ToLower(strings.TrimPrefix(color, "#"))
+		}
+	}
+	return ""
+}
+
+// withLabelColor returns src with the LabelColors color for the target
+// label name, if one is configured
+func (m *Migrator) withLabelColor(name string, src *github.Label) *github.Label {
+	color := m.labelColor(name)
+	if color == "" {
+		return src
+	}
+	l := *src
+	l.Color = &color
+	return &l
+}
+
+// withAddLabels returns labels with the AddLabels that it lacks appended
+func (m *Migrator) withAddLabels(labels []string) []string {
+	have := map[string]bool{}
+	for _, l := range labels {
+		have[strings.ToLower(l)] = true
+	}
+	for _, l := range m.cfg.AddLabels {
+		if !have[strings.ToLower(l)] {
+			have[strings.ToLower(l)] = true
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+// LabelInfo describes a label that would be created in the target
+type LabelInfo struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+// LabelMismatch describes a label whose color or description differs between the repos
+type LabelMismatch struct {
+	Name              string `json:"name"`
+	SourceColor       string `json:"source_color"`
+	TargetColor       string `json:"target_color"`
+	SourceDescription string `json:"source_description"`
+	TargetDescription string `json:"target_description"`
+}
+
+// LabelDiff compares the labels a migration would apply with the target's labels
+type LabelDiff struct {
+	Create     []LabelInfo     `json:"create"`
+	Mismatched []LabelMismatch `json:"mismatched"`
+	TargetOnly []string        `json:"target_only"`
+	// Invalid are names GitHub rejects, which a run drops or fails on by
+	// the OnInvalidLabel policy
+	Invalid []string `json:"invalid"`
+}
+
+// loadTargetLabels indexes the labels of the target by lower case name
+func (m *Migrator) loadTargetLabels(ctx context.Context) error {
+	labels, err := m.listLabels(ctx, m.cfg.To)
+	if err != nil {
+		return err
+	}
+	m.targetLabels = map[string]*github.Label{}
+	for _, l := range labels {
+		m.targetLabels[strings.ToLower(l.GetName())] = l
+	}
+	m.collisions = map[string]string{}
+	return nil
+}
+
+// listLabels returns every label defined in repo
+func (m *Migrator) listLabels(ctx context.Context, repo Repo) ([]*github.Label, error) {
+	var labels []*github.Label
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := m.clientFor(repo).Issues.ListLabels(ctx, repo.Owner, repo.Name, opts)
+		if err != nil {
+			return nil, newAPIError("list labels", err)
+		}
+		labels = append(labels, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return labels, nil
+}
+
+// sourceLabel finds the source label a synced label name came from
+func (m *Migrator) sourceLabel(source []*github.Label, name string) *github.Label {
+	for _, l := range source {
+		if synced, ok := m.syncedName(l.GetName()); ok && synced == name {
+			return l
+		}
+	}
+	return &github.Label{Name: &name}
+}
+
+// ensureLabels creates the labels of req that are missing from the target,
+// with the color and description of their source label. Labels that can not
+// be created are handled by the OnLabelError policy, and returned if dropped.
+// Labels that differ from an existing target label are handled by the
+// OnLabelCollision policy, and returned as collisions.
+func (m *Migrator) ensureLabels(ctx context.Context, source []*github.Label, req *github.IssueRequest) (dropped, collisions []string, err error) {
+	if req.Labels == nil {
+		return nil, nil, nil
+	}
+	var kept []string
+	for _, name := range *req.Labels {
+		srcName := name
+		fixed, problem := m.fixLabel(name)
+		if problem != "" {
+			if m.cfg.OnInvalidLabel == InvalidLabelFail {
+				return nil, nil, fmt.Errorf("%w: %q %s", ErrInvalidLabel, name, problem)
+			}
+			if fixed == "" {
+				m.printf("Label %q %s, dropping it\n", name, problem)
+				dropped = append(dropped, name)
+				continue
+			}
+			m.printf("Label %q %s, using %q\n", name, problem, fixed)
+			name = fixed
+		}
+		src := m.withLabelColor(name, m.sourceLabel(source, srcName))
+		target, ok := m.targetLabels[strings.ToLower(name)]
+		if ok && src.GetColor() != "" && labelsDiffer(src, target) {
+			collisions = append(collisions, name)
+			name, err = m.resolveCollision(ctx, src, name)
+			if err != nil {
+				return nil, nil, err
+			}
+			_, ok = m.targetLabels[strings.ToLower(name)]
+		}
+		if ok {
+			kept = append(kept, name)
+			continue
+		}
+
+		if err := m.createLabel(ctx, name, src); err != nil {
+			switch m.cfg.OnLabelError {
+			case LabelErrorFail:
+				return nil, nil, err
+			case LabelErrorWarn:
+				m.printf("Warning: could not create label %q in %s, dropping it: %v\n", name, m.cfg.To, err)
+			}
+			dropped = append(dropped, name)
+			continue
+		}
+		kept = append(kept, name)
+	}
+	req.Labels = &kept
+	return dropped, collisions, nil
+}
+
+// labelsDiffer reports whether two labels of the same name differ in color or description
+func labelsDiffer(a, b *github.Label) bool {
+	return !strings.EqualFold(a.GetColor(), b.GetColor()) || a.GetDescription() != b.GetDescription()
+}
+
+// createLabel creates name in the target with the color and description of src
+func (m *Migrator) createLabel(ctx context.Context, name string, src *github.Label) error {
+	to := m.cfg.To
+	color := src.GetColor()
+	if color == "" {
+		color = defaultLabelColor
+	}
+	var created *github.Label
+	err := m.withSecondaryRetry(ctx, func() (err error) {
+		created, _, err = m.client.Issues.CreateLabel(ctx, to.Owner, to.Name, &github.Label{
+			Name:        &name,
+			Color:       &color,
+			Description: src.Description,
+		})
+		return err
+	})
+	if err != nil {
+		return newAPIError("create label "+name, err)
+	}
+	m.targetLabels[strings.ToLower(name)] = created
+	return nil
+}
+
+// renamedLabel returns the name a label is renamed to on collision, shortened
+// so it stays within maxLabelLength
+func renamedLabel(name string) string {
+	r := []rune(name)
+	if keep := maxLabelLength - len(collisionSuffix); len(r) > keep {
+		r = r[:keep]
+	}
+	return strings.TrimSpace(string(r)) + collisionSuffix
+}
+
+// resolveCollision applies the OnLabelCollision policy to a synced label that
+// differs from the target label of the same name, and returns the name to apply
+func (m *Migrator) resolveCollision(ctx context.Context, src *github.Label, name string) (string, error) {
+	if resolved, ok := m.collisions[name]; ok {
+		return resolved, nil
+	}
+	target := m.targetLabels[strings.ToLower(name)]
+	to := m.cfg.To
+	resolved := name
+	switch m.cfg.OnLabelCollision {
+	case LabelCollisionReuse:
+		// a LabelColors color is still applied to the reused label, as the
+		// colors are meant to be normalized across the target
+		color := m.labelColor(name)
+		if color == "" || strings.EqualFold(color, target.GetColor()) {
+			m.printf("Label %q differs in %s (color #%s, description %q), reusing it\n", name, to, target.GetColor(), target.GetDescription())
+			break
+		}
+		m.printf("Label %q differs in %s, reusing it with color #%s\n", name, to, color)
+		if err := m.editLabel(ctx, target, &github.Label{Color: &color}); err != nil {
+			return "", err
+		}
+	case LabelCollisionUpdate:
+		m.printf("Label %q differs in %s, updating it to match the source\n", name, to)
+		if err := m.editLabel(ctx, target, &github.Label{Color: src.Color, Description: src.Description}); err != nil {
+			return "", err
+		}
+	case LabelCollisionRename:
+		resolved = renamedLabel(name)
+		m.printf("Label %q differs in %s, using %q instead\n", name, to, resolved)
+	}
+	m.collisions[name] = resolved
+	return resolved, nil
+}
+
+// editLabel applies the color and description set in edit to the target label
+func (m *Migrator) editLabel(ctx context.Context, target, edit *github.Label) error {
+	to := m.cfg.To
+	var updated *github.Label
+	err := m.withSecondaryRetry(ctx, func() (err error) {
+		updated, _, err = m.client.Issues.EditLabel(ctx, to.Owner, to.Name, target.GetName(), edit)
+		return err
+	})
+	if err != nil {
+		return newAPIError("update label "+target.GetName(), err)
+	}
+	m.targetLabels[strings.ToLower(target.GetName())] = updated
+	return nil
+}
+
+// DiffLabels compares the labels a migration would apply, after banning and
+// mapping, against the target's label set
+func (m *Migrator) DiffLabels(ctx context.Context) (LabelDiff, error) {
+	if err := validateLabelColors(m.cfg.LabelColors); err != nil {
+		return LabelDiff{}, err
+	}
+	if err := validateInvalidLabel(m.cfg.OnInvalidLabel); err != nil {
+		return LabelDiff{}, err
+	}
+	source, err := m.listLabels(ctx, m.cfg.From)
+	if err != nil {
+		return LabelDiff{}, err
+	}
+	target, err := m.listLabels(ctx, m.cfg.To)
+	if err != nil {
+		return LabelDiff{}, err
+	}
+	return m.diffLabels(source, target), nil
+}
+
+// diffLabels compares label sets. Names are compared case-insensitively, as GitHub does.
+func (m *Migrator) diffLabels(source, target []*github.Label) LabelDiff {
+	var diff LabelDiff
+
+	targetByName := map[string]*github.Label{}
+	for _, l := range target {
+		targetByName[strings.ToLower(l.GetName())] = l
+	}
+
+	synced := m.assertAndSyncLabels(source)
+	wanted := map[string]bool{}
+	for i, name := range m.withAddLabels(synced) {
+		srcName := name
+		fixed, _ := m.fixLabel(name)
+		if fixed == "" {
+			diff.Invalid = append(diff.Invalid, name)
+			continue
+		}
+		name = fixed
+		key := strings.ToLower(name)
+		if wanted[key] {
+			continue
+		}
+		wanted[key] = true
+
+		// the from-label and the added labels have no source label, and all
+		// take their LabelColors color like in a run
+		fromSource := i > 0 && i < len(synced)
+		src := &github.Label{Name: &name}
+		if fromSource {
+			src = m.sourceLabel(source, srcName)
+		}
+		src = m.withLabelColor(name, src)
+
+		t, ok := targetByName[key]
+		if !ok {
+			diff.Create = append(diff.Create, LabelInfo{
+				Name:        name,
+				Color:       src.GetColor(),
+				Description: src.GetDescription(),
+			})
+			continue
+		}
+		if src.GetColor() != "" && labelsDiffer(src, t) {
+			diff.Mismatched = append(diff.Mismatched, LabelMismatch{
+				Name:              name,
+				SourceColor:       src.GetColor(),
+				TargetColor:       t.GetColor(),
+				SourceDescription: src.GetDescription(),
+				TargetDescription: t.GetDescription(),
+			})
+		}
+	}
+
+	for key, l := range targetByName {
+		if !wanted[key] {
+			diff.TargetOnly = append(diff.TargetOnly, l.GetName())
+		}
+	}
+	sort.Strings(diff.TargetOnly)
+
+	return diff
+}
@@ -0,0 +1,227 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v36/github"
+)
+
+func TestWithAddLabels(t *testing.T) {
+	m := New(Config{AddLabels: []string{"imported", "Triage"}})
+	tests := []struct {
+		name   string
+		labels []string
+		want   []string
+	}{
+		{"none synced", nil, []string{"imported", "Triage"}},
+		{"appended", []string{"bug"}, []string{"bug", "imported", "Triage"}},
+		{"already synced", []string{"imported"}, []string{"imported", "Triage"}},
+		{"case variant synced", []string{"triage", "IMPORTED"}, []string{"triage", "IMPORTED"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.withAddLabels(tt.labels); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("withAddLabels(%q) = %q, want %q", tt.labels, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateIssueAddsLabels(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.addIssue(testSource, &github.Issue{
+		Title:  github.String("Crash on start"),
+		Labels: []*github.Label{{Name: github.String("bug")}, {Name: github.String("triage")}},
+	})
+
+	m := newTestMigrator(t, f, Config{AddLabels: []string{"imported", "Triage"}})
+	if _, err := m.MigrateIssue(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	got := labelNames(f.repo(testTarget).issues[0].Labels)
+	want := []string{DefaultMigratedFromLabel, "bug", "triage", "imported"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("target issue has labels %q, want %q", got, want)
+	}
+}
+
+func TestEnsureLabelsInvalidNames(t *testing.T) {
+	long := strings.Repeat("x", maxLabelLength) + " overflow"
+	tests := []struct {
+		name    string
+		policy  string
+		labels  []string
+		want    []string
+		dropped []string
+		err     error
+	}{
+		{"skip too long", InvalidLabelSkip, []string{"bug", long}, []string{"bug"}, []string{long}, nil},
+		{"skip empty", InvalidLabelSkip, []string{"bug", "  "}, []string{"bug"}, []string{"  "}, nil},
+		{"fail too long", InvalidLabelFail, []string{"bug", long}, nil, nil, ErrInvalidLabel},
+		{"fail empty", InvalidLabelFail, []string{"bug", ""}, nil, nil, ErrInvalidLabel},
+		{"sanitize too long", InvalidLabelSanitize, []string{"bug", long}, []string{"bug", strings.Repeat("x", maxLabelLength)}, nil, nil},
+		{"sanitize empty", InvalidLabelSanitize, []string{"bug", " "}, []string{"bug"}, []string{" "}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeGitHub(t)
+			m := newTestMigrator(t, f, Config{OnInvalidLabel: tt.policy})
+			ctx := context.Background()
+			if err := m.loadTargetLabels(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			labels := append([]string{}, tt.labels...)
+			req := &github.IssueRequest{Labels: &labels}
+			dropped, _, err := m.ensureLabels(ctx, nil, req)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ensureLabels(%q) returned error %v, want %v", tt.labels, err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(*req.Labels, tt.want) || !reflect.DeepEqual(dropped, tt.dropped) {
+				t.Errorf("ensureLabels(%q) kept %q and dropped %q, want %q and %q", tt.labels, *req.Labels, dropped, tt.want, tt.dropped)
+			}
+			created := labelNames(f.repo(testTarget).labels)
+			if !reflect.DeepEqual(created, tt.want) {
+				t.Errorf("created labels %q, want %q", created, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenamedLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"bug", "bug-migrated"},
+		{strings.Repeat("x", maxLabelLength), strings.Repeat("x", maxLabelLength-len(collisionSuffix)) + collisionSuffix},
+		{strings.Repeat("é", 45), strings.Repeat("é", maxLabelLength-len(collisionSuffix)) + collisionSuffix},
+		{strings.Repeat("x", 40) + " " + strings.Repeat("y", 9), strings.Repeat("x", 40) + collisionSuffix},
+	}
+	for _, tt := range tests {
+		got := renamedLabel(tt.name)
+		if got != tt.want {
+			t.Errorf("renamedLabel(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if problem := labelProblem(got); problem != "" {
+			t.Errorf("renamedLabel(%q) = %q, which %s", tt.name, got, problem)
+		}
+	}
+}
+
+func TestEnsureLabelsColorMap(t *testing.T) {
+	f := newFakeGitHub(t)
+	target := f.repo(testTarget)
+	target.labels = []*github.Label{{Name: github.String("Bug"), Color: github.String("ffffff"), Description: github.String("Something is broken")}}
+	source := []*github.Label{
+		{Name: github.String("bug"), Color: github.String("ee0701")},
+		{Name: github.String("docs"), Color: github.String("c5def5")},
+	}
+
+	m := newTestMigrator(t, f, Config{LabelColors: map[string]string{"bug": "D73A4A", "docs": "#0075ca"}})
+	ctx := context.Background()
+	if err := m.loadTargetLabels(ctx); err != nil {
+		t.Fatal(err)
+	}
+	labels := []string{"bug", "docs"}
+	req := &github.IssueRequest{Labels: &labels}
+	if _, _, err := m.ensureLabels(ctx, source, req); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"Bug": "d73a4a", "docs": "0075ca"}
+	if len(target.labels) != len(want) {
+		t.Fatalf("target has labels %q, want %d", labelNames(target.labels), len(want))
+	}
+	for _, l := range target.labels {
+		if l.GetColor() != want[l.GetName()] {
+			t.Errorf("label %q has color %s, want %s", l.GetName(), l.GetColor(), want[l.GetName()])
+		}
+	}
+	if d := target.labels[0].GetDescription(); d != "Something is broken" {
+		t.Errorf("reused label has description %q, want it kept", d)
+	}
+}
+
+func TestDiffLabels(t *testing.T) {
+	long := strings.Repeat("x", maxLabelLength) + " overflow"
+	source := []*github.Label{
+		{Name: github.String("bug"), Color: github.String("ee0701"), Description: github.String("Something is broken")},
+		{Name: github.String("docs"), Color: github.String("c5def5")},
+	}
+	target := []*github.Label{
+		{Name: github.String("Bug"), Color: github.String("ee0701"), Description: github.String("Something is broken")},
+		{Name: github.String("wontfix"), Color: github.String("ffffff")},
+	}
+	tests := []struct {
+		name  string
+		cfg   Config
+		extra []*github.Label
+		want  LabelDiff
+	}{
+		{
+			name: "synced labels",
+			want: LabelDiff{
+				Create:     []LabelInfo{{Name: DefaultMigratedFromLabel}, {Name: "docs", Color: "c5def5"}},
+				TargetOnly: []string{"wontfix"},
+			},
+		},
+		{
+			name: "added labels",
+			cfg:  Config{AddLabels: []string{"imported", "wontfix"}},
+			want: LabelDiff{
+				Create: []LabelInfo{{Name: DefaultMigratedFromLabel}, {Name: "docs", Color: "c5def5"}, {Name: "imported"}},
+			},
+		},
+		{
+			name: "mapped colors",
+			cfg:  Config{LabelColors: map[string]string{"bug": "d73a4a", "docs": "#0075CA", DefaultMigratedFromLabel: "ededed"}},
+			want: LabelDiff{
+				Create: []LabelInfo{{Name: DefaultMigratedFromLabel, Color: "ededed"}, {Name: "docs", Color: "0075ca"}},
+				Mismatched: []LabelMismatch{{
+					Name:              "bug",
+					SourceColor:       "d73a4a",
+					TargetColor:       "ee0701",
+					SourceDescription: "Something is broken",
+					TargetDescription: "Something is broken",
+				}},
+				TargetOnly: []string{"wontfix"},
+			},
+		},
+		{
+			name:  "sanitized names",
+			extra: []*github.Label{{Name: github.String(long), Color: github.String("5319e7")}, {Name: github.String("  ")}},
+			want: LabelDiff{
+				Create:     []LabelInfo{{Name: DefaultMigratedFromLabel}, {Name: "docs", Color: "c5def5"}, {Name: strings.Repeat("x", maxLabelLength), Color: "5319e7"}},
+				TargetOnly: []string{"wontfix"},
+				Invalid:    []string{"  "},
+			},
+		},
+		{
+			name:  "skipped names",
+			cfg:   Config{OnInvalidLabel: InvalidLabelSkip},
+			extra: []*github.Label{{Name: github.String(long), Color: github.String("5319e7")}},
+			want: LabelDiff{
+				Create:     []LabelInfo{{Name: DefaultMigratedFromLabel}, {Name: "docs", Color: "c5def5"}},
+				TargetOnly: []string{"wontfix"},
+				Invalid:    []string{long},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := append(source, tt.extra...)
+			got := New(tt.cfg).diffLabels(source, target)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffLabels() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
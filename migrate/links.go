@@ -0,0 +1,54 @@
+package migrate
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// InternalLinkPlaceholder replaces the links that are removed rather than mapped
+const InternalLinkPlaceholder = "[internal link removed]"
+
+var linkRe = regexp.MustCompile(`https?://[^\s<>()\[\]"'` + "`" + `]+`)
+
+// rewriteLinks maps the hosts of links through URLMap. Links to hosts mapped
+// to "" and, with RewriteInternalLinks, other links holding blocklist terms
+// are replaced by InternalLinkPlaceholder.
+func (m *Migrator) rewriteLinks(s string) string {
+	return linkRe.ReplaceAllStringFunc(s, func(link string) string {
+		if u, err := url.Parse(link); err == nil {
+			for from, to := range m.cfg.URLMap {
+				if !strings.EqualFold(u.Host, from) {
+					continue
+				}
+				if to == "" {
+					return InternalLinkPlaceholder
+				}
+				return strings.Replace(link, u.Host, to, 1)
+			}
+		}
+		if m.cfg.RewriteInternalLinks && m.internalTerm(link) != "" {
+			return InternalLinkPlaceholder
+		}
+		return link
+	})
+}
+
+// rewriteIssueLinks returns copies of issue and its comments with the links
+// of their bodies rewritten
+func (m *Migrator) rewriteIssueLinks(issue *github.Issue, comments []*github.IssueComment) (*github.Issue, []*github.IssueComment) {
+	body := m.rewriteLinks(issue.GetBody())
+	rewritten := *issue
+	rewritten.Body = &body
+
+	out := make([]*github.IssueComment, len(comments))
+	for i, c := range comments {
+		cb := m.rewriteLinks(c.GetBody())
+		rc := *c
+		rc.Body = &cb
+		out[i] = &rc
+	}
+	return &rewritten, out
+}
@@ -0,0 +1,1082 @@
+// Package migrate safely migrates issues from one GitHub repository to another.
+//
+// It allows one to open source a previously internal project by syncing the
+// labels, issues and comments to a new project repo, with every issue
+// reviewed through a Prompter along the way.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/go-github/v36/github"
+	"golang.org/x/oauth2"
+)
+
+// Migrator migrates issues between the repos of its Config
+type Migrator struct {
+	cfg Config
+	// client writes to the target, source reads the source. They are the
+	// same unless the source lives on another GitHub instance.
+	client *github.Client
+	source *github.Client
+
+	// state rebuilt at the start of each run
+	migrated        map[int]*github.Issue
+	milestoneNumber int
+	// milestones holds the target milestone numbers by title
+	milestones map[string]int
+	// teams caches which target team slugs exist
+	teams     map[string]bool
+	templates []issueTemplate
+	template  *issueTemplate
+	// issueTypes holds the target issue type IDs by lowercased name
+	issueTypes map[string]string
+	// targetLabels holds the target's labels by lowercased name
+	targetLabels map[string]*github.Label
+	// collisions maps source label names that collided with a target label
+	// to the name resolved for them
+	collisions map[string]string
+	// assignees caches which logins can be assigned in the target
+	assignees map[string]bool
+	// comments holds the source comments prefetched over GraphQL by issue number
+	comments map[int][]*github.IssueComment
+	// attribution renders each collated comment
+	attribution *template.Template
+	// preamble renders the text prepended to each migrated body, if any
+	preamble *template.Template
+	// sourcePrivate is set by preflight when the source repo is private
+	sourcePrivate bool
+	// blocklist is the compiled Blocklist
+	blocklist []blockTerm
+	// sourcePins holds the numbers of the pinned source issues
+	sourcePins map[int]bool
+	// targetPinned counts the pinned target issues
+	targetPinned int
+	// stats measures the work done so far
+	stats *Stats
+	// preserveNumbers is set while MigrateAll preserves source numbers
+	preserveNumbers bool
+}
+
+// AllOptions controls which issues MigrateAll considers
+type AllOptions struct {
+	// IncludeClosed also migrates closed issues
+	IncludeClosed bool
+	// Export receives closed issues as JSON lines instead of creating them
+	// in the target. It requires IncludeClosed.
+	Export io.Writer
+	// PreserveNumbers creates closed placeholder issues in an empty target
+	// so migrated issues keep their source numbers.
+	PreserveNumbers bool
+	// PreserveOrder migrates issues oldest first, so target numbers follow
+	// the source chronology
+	PreserveOrder bool
+	// Since limits the run to issues created at or after it
+	Since time.Time
+	// Yes starts the run without confirming its summary first
+	Yes bool
+	// Query selects the issues with a GitHub search query, scoped to the
+	// source repo, instead of listing them
+	Query string
+	// MinComments skips issues with fewer comments
+	MinComments int
+	// MinReactions skips issues with fewer reactions
+	MinReactions int
+	// ResumeFrom skips the issues processed before the source issue with
+	// this number, in the order of the run, 0 to start at the beginning
+	ResumeFrom int
+	// Plan receives one PlannedIssue per line describing what the run would
+	// do, instead of migrating anything
+	Plan io.Writer
+	// PlanLabels creates the labels planned issues would sync in the target.
+	// It is the only change a Plan makes.
+	PlanLabels bool
+}
+
+// New returns a Migrator for cfg
+func New(cfg Config) *Migrator {
+	cfg.setDefaults()
+
+	transport := http.DefaultTransport
+	var timeout time.Duration
+	if cfg.HTTPClient != nil {
+		if cfg.HTTPClient.Transport != nil {
+			transport = cfg.HTTPClient.Transport
+		}
+		timeout = cfg.HTTPClient.Timeout
+	}
+	stats := &Stats{Outcomes: map[Status]int{}}
+	newClient := func(token string, baseURL *url.URL) *github.Client {
+		tc := &http.Client{
+			Transport: &oauth2.Transport{
+				Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}),
+				Base:   countingTransport{base: transport, count: &stats.APICalls},
+			},
+			Timeout: timeout,
+		}
+		client := github.NewClient(tc)
+		client.UserAgent = cfg.UserAgent
+		if baseURL != nil {
+			u := *baseURL
+			if !strings.HasSuffix(u.Path, "/") {
+				u.Path += "/"
+			}
+			client.BaseURL = &u
+		}
+		return client
+	}
+
+	client := newClient(cfg.Token, cfg.TargetBaseURL)
+	source := client
+	if cfg.SourceBaseURL != nil || cfg.SourceToken != "" {
+		token := cfg.SourceToken
+		if token == "" {
+			token = cfg.Token
+		}
+		source = newClient(token, cfg.SourceBaseURL)
+	}
+	return &Migrator{
+		cfg:    cfg,
+		client: client,
+		source: source,
+		stats:  stats,
+	}
+}
+
+// Client returns the GitHub client the Migrator writes to the target with
+func (m *Migrator) Client() *github.Client {
+	return m.client
+}
+
+// SourceClient returns the GitHub client the Migrator reads the source with.
+// It is Client unless the source has its own base URL or token.
+func (m *Migrator) SourceClient() *github.Client {
+	return m.source
+}
+
+// clientFor returns the client for requests to repo
+func (m *Migrator) clientFor(repo Repo) *github.Client {
+	if repo == m.cfg.From {
+		return m.source
+	}
+	return m.client
+}
+
+func (m *Migrator) printf(format string, a ...interface{}) {
+	fmt.Fprintf(m.cfg.Out, format, a...)
+}
+
+func (m *Migrator) println(a ...interface{}) {
+	fmt.Fprintln(m.cfg.Out, a...)
+}
+
+func (m *Migrator) report(res Result) {
+	m.stats.Outcomes[res.Status]++
+	if m.cfg.OnResult != nil {
+		m.cfg.OnResult(res)
+	}
+	m.emit(EventDone, res)
+}
+
+func (m *Migrator) validate() error {
+	if m.cfg.Login == "" {
+		return ErrMissingLogin
+	}
+	if m.cfg.Prompter == nil && !m.cfg.AutoClean {
+		return fmt.Errorf("%w: a Prompter is required", ErrInvalidConfig)
+	}
+	switch m.cfg.OnLabelCollision {
+	case LabelCollisionReuse, LabelCollisionRename, LabelCollisionUpdate:
+	default:
+		return fmt.Errorf("%w: label collision policy must be %s, %s or %s, got %q", ErrInvalidConfig, LabelCollisionReuse, LabelCollisionRename, LabelCollisionUpdate, m.cfg.OnLabelCollision)
+	}
+	switch m.cfg.OnLabelError {
+	case LabelErrorSkip, LabelErrorWarn, LabelErrorFail:
+	default:
+		return fmt.Errorf("%w: label error policy must be %s, %s or %s, got %q", ErrInvalidConfig, LabelErrorSkip, LabelErrorWarn, LabelErrorFail, m.cfg.OnLabelError)
+	}
+	if err := validateInvalidLabel(m.cfg.OnInvalidLabel); err != nil {
+		return err
+	}
+	for _, l := range m.cfg.AddLabels {
+		if problem := labelProblem(l); problem != "" {
+			return fmt.Errorf("%w: added label %q %s", ErrInvalidConfig, l, problem)
+		}
+	}
+	switch m.cfg.OnAssigneeError {
+	case AssigneeErrorDrop, AssigneeErrorSkip, AssigneeErrorFail:
+	default:
+		return fmt.Errorf("%w: assignee error policy must be %s, %s or %s, got %q", ErrInvalidConfig, AssigneeErrorDrop, AssigneeErrorSkip, AssigneeErrorFail, m.cfg.OnAssigneeError)
+	}
+	if err := m.validateContent(); err != nil {
+		return err
+	}
+	switch m.cfg.CodeBlockPolicy {
+	case CodeBlockScan, CodeBlockStrict, CodeBlockIgnore:
+	default:
+		return fmt.Errorf("%w: code block policy must be %s, %s or %s, got %q", ErrInvalidConfig, CodeBlockScan, CodeBlockStrict, CodeBlockIgnore, m.cfg.CodeBlockPolicy)
+	}
+	if err := validateLabelColors(m.cfg.LabelColors); err != nil {
+		return err
+	}
+	if m.cfg.ConfirmTarget && m.cfg.AutoClean {
+		return fmt.Errorf("%w: confirming the target requires running interactively", ErrInvalidConfig)
+	}
+	if m.cfg.SourceReadOnly && len(m.cfg.PruneSourceLabels) > 0 {
+		return fmt.Errorf("%w: pruning source labels writes to a read-only source", ErrInvalidConfig)
+	}
+	if m.cfg.SyncMilestones && m.cfg.TargetMilestone != "" {
+		return fmt.Errorf("%w: milestones can not be both synced and set to a target milestone", ErrInvalidConfig)
+	}
+	if m.cfg.NoTarget && m.cfg.MarkdownDir == "" {
+		return fmt.Errorf("%w: archiving without a target requires a markdown directory", ErrInvalidConfig)
+	}
+	if m.cfg.Overflow != OverflowTruncate && m.cfg.Overflow != OverflowComments {
+		return fmt.Errorf("%w: overflow must be %s or %s, got %q", ErrInvalidConfig, OverflowTruncate, OverflowComments, m.cfg.Overflow)
+	}
+	if m.cfg.OnLargeComment != LargeCommentCollapse && m.cfg.OnLargeComment != LargeCommentTruncate {
+		return fmt.Errorf("%w: large comment policy must be %s or %s, got %q", ErrInvalidConfig, LargeCommentCollapse, LargeCommentTruncate, m.cfg.OnLargeComment)
+	}
+	if m.cfg.LabelHistoryLimit < 0 {
+		return fmt.Errorf("%w: the label history limit can not be negative", ErrInvalidConfig)
+	}
+	if m.cfg.MaxBodyLength <= bodyReserve {
+		return fmt.Errorf("%w: the maximum body length must be over %d", ErrInvalidConfig, bodyReserve)
+	}
+	if m.cfg.CommentStyle != CommentStyleBlock && m.cfg.CommentStyle != CommentStyleInline {
+		return fmt.Errorf("%w: comment style must be %s or %s, got %q", ErrInvalidConfig, CommentStyleBlock, CommentStyleInline, m.cfg.CommentStyle)
+	}
+	attribution, err := parseAttribution(m.cfg.CommentAttribution)
+	if err != nil {
+		return err
+	}
+	m.attribution = attribution
+	if m.preamble, err = parsePreamble(m.cfg.BodyPreamble); err != nil {
+		return err
+	}
+	if m.blocklist, err = compileBlocklist(m.cfg.Blocklist); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateContent checks the settings preparing source content
+func (m *Migrator) validateContent() error {
+	if m.cfg.OnPrivateAsset != AssetActionWarn && m.cfg.OnPrivateAsset != AssetActionPlaceholder {
+		return fmt.Errorf("%w: private asset action must be %s or %s, got %q", ErrInvalidConfig, AssetActionWarn, AssetActionPlaceholder, m.cfg.OnPrivateAsset)
+	}
+	if m.cfg.Emoji != EmojiKeep && m.cfg.Emoji != EmojiStrip {
+		return fmt.Errorf("%w: emoji must be %s or %s, got %q", ErrInvalidConfig, EmojiKeep, EmojiStrip, m.cfg.Emoji)
+	}
+	return nil
+}
+
+// preflight checks both repos accept issue reads and writes before any work is done
+func (m *Migrator) preflight(ctx context.Context) error {
+	repos := []Repo{m.cfg.From, m.cfg.To}
+	if m.cfg.NoTarget {
+		repos = repos[:1]
+	}
+	for _, r := range repos {
+		repo, resp, err := m.clientFor(r).Repositories.Get(ctx, r.Owner, r.Name)
+		if err != nil {
+			return newAPIError("get repo "+r.String(), err)
+		}
+		if err := checkScope(r, repo, resp.Header); err != nil {
+			return err
+		}
+		if r == m.cfg.From {
+			m.sourcePrivate = repo.GetPrivate()
+		}
+		if !repo.GetHasIssues() {
+			return fmt.Errorf("%w on %s; enable them before migrating", ErrIssuesDisabled, r)
+		}
+	}
+	return nil
+}
+
+// prepare loads the target state a run depends on
+func (m *Migrator) prepare(ctx context.Context) error {
+	if m.cfg.NoTarget {
+		return nil
+	}
+	if err := m.checkAssigneeFallback(ctx); err != nil {
+		return err
+	}
+	var err error
+	m.milestones = nil
+	m.milestoneNumber = 0
+	if m.cfg.TargetMilestone != "" {
+		m.milestoneNumber, err = m.ensureMilestone(ctx, &github.Milestone{Title: &m.cfg.TargetMilestone})
+		if err != nil {
+			return err
+		}
+	}
+	m.templates, err = m.loadTemplates(ctx)
+	if err != nil {
+		return err
+	}
+	m.template = nil
+	if m.cfg.TargetTemplate != "" {
+		m.template, err = findTemplate(m.templates, m.cfg.TargetTemplate)
+		if err != nil {
+			return err
+		}
+	}
+	if err := m.loadTargetLabels(ctx); err != nil {
+		return err
+	}
+	m.issueTypes = nil
+	if m.cfg.IncludeTypes {
+		m.issueTypes = m.loadIssueTypes(ctx)
+	}
+	if m.cfg.PreservePins {
+		m.loadPins(ctx)
+	}
+	if m.cfg.ReplicateReactions {
+		m.println("Note: replicated reactions are attributed to the token's user, one of each kind")
+	}
+	m.migrated, err = m.indexMigrated(ctx)
+	return err
+}
+
+// MigrateIssue migrates a single source issue
+func (m *Migrator) MigrateIssue(ctx context.Context, number int) (Result, error) {
+	if err := m.validate(); err != nil {
+		return Result{}, err
+	}
+	if err := m.preflight(ctx); err != nil {
+		return Result{}, err
+	}
+	from := m.cfg.From
+
+	issue, _, err := m.source.Issues.Get(ctx, from.Owner, from.Name, number)
+	if err != nil {
+		return Result{}, newAPIError("get issue", err)
+	}
+	if issue.IsPullRequest() {
+		return Result{}, ErrIsPullRequest
+	}
+	for _, l := range issue.Labels {
+		if *l.Name == m.cfg.SkipLabel {
+			return Result{}, fmt.Errorf("%w: %s", ErrSkipLabel, m.cfg.SkipLabel)
+		}
+	}
+
+	if err := m.prepare(ctx); err != nil {
+		return Result{}, err
+	}
+	if _, migrated := m.migrated[number]; m.cfg.FailOnInternal && !migrated {
+		if err := m.scanIssues(ctx, []*github.Issue{issue}); err != nil {
+			return Result{}, err
+		}
+	}
+	m.preserveNumbers = false
+	res, err := m.migrateAndReport(ctx, issue)
+	if err == nil && res.Status == StatusSecurity {
+		return res, fmt.Errorf("%w: %s", ErrSecurityIssue, res.Error)
+	}
+	return res, err
+}
+
+// MigrateAll migrates every issue of the source repo that has not been
+// migrated or marked with the skip label
+func (m *Migrator) MigrateAll(ctx context.Context, opts AllOptions) ([]Result, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	if opts.Export != nil && !opts.IncludeClosed {
+		return nil, fmt.Errorf("%w: exporting closed issues requires including them", ErrInvalidConfig)
+	}
+	m.preserveNumbers = opts.PreserveNumbers
+	if opts.PreserveNumbers && m.cfg.NoTarget {
+		return nil, fmt.Errorf("%w: preserving numbers requires a target", ErrInvalidConfig)
+	}
+	if opts.PreserveNumbers && m.cfg.AutoClean {
+		return nil, fmt.Errorf("%w: preserving numbers must be confirmed interactively", ErrInvalidConfig)
+	}
+	if opts.PreserveNumbers && opts.Plan != nil {
+		return nil, fmt.Errorf("%w: preserving numbers can not be planned", ErrInvalidConfig)
+	}
+	if opts.PlanLabels && (opts.Plan == nil || m.cfg.NoTarget) {
+		return nil, fmt.Errorf("%w: creating labels of a plan requires a plan and a target", ErrInvalidConfig)
+	}
+	if opts.PreserveNumbers && opts.ResumeFrom != 0 {
+		return nil, fmt.Errorf("%w: preserving numbers can not resume part way", ErrInvalidConfig)
+	}
+	if err := m.preflight(ctx); err != nil {
+		return nil, err
+	}
+
+	// planning must not create the target milestone
+	if opts.Plan == nil {
+		if err := m.prepare(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	state := "open"
+	if opts.IncludeClosed {
+		state = "all"
+	}
+	var (
+		issues []*github.Issue
+		err    error
+	)
+	if opts.Query != "" {
+		issues, err = m.searchIssues(ctx, opts.Query, opts.IncludeClosed)
+	} else {
+		issues, err = m.listIssues(ctx, state, opts.Since)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.ResumeFrom != 0 {
+		resumed := resumeFrom(issues, opts)
+		m.printf("Resuming from issue %d, skipping %d issues before it\n", opts.ResumeFrom, len(issues)-len(resumed))
+		issues = resumed
+	}
+
+	m.comments = nil
+	if m.cfg.UseGraphQL {
+		states := []string{"OPEN"}
+		if opts.IncludeClosed {
+			states = append(states, "CLOSED")
+		}
+		m.comments = m.prefetchComments(ctx, states)
+	}
+
+	if opts.Plan != nil {
+		return nil, m.writePlan(ctx, opts.Plan, issues, opts)
+	}
+
+	if opts.PreserveNumbers {
+		next, err := m.nextIssueNumber(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if next != 1 {
+			return nil, fmt.Errorf("%w: preserving numbers requires an empty repo, %s already has issues", ErrTargetNotEmpty, m.cfg.To)
+		}
+		m.println("WARNING: preserving numbers will create a closed placeholder issue in the target for every")
+		m.println("source number that is not migrated (skipped issues, pull requests, deleted issues).")
+		m.println("These placeholders can not be deleted without admin access to the target repo.")
+		ok, err := m.cfg.Prompter.Confirm("Create placeholder issues?")
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrUserAborted
+		}
+	}
+	// numbers can only be burned forwards, so preserving them migrates oldest first too
+	if opts.PreserveNumbers || opts.PreserveOrder {
+		sort.Slice(issues, func(a, b int) bool {
+			return *issues[a].Number < *issues[b].Number
+		})
+	}
+
+	if m.cfg.FailOnInternal {
+		var pending []*github.Issue
+		for _, i := range issues {
+			if m.willCreate(i, opts) {
+				pending = append(pending, i)
+			}
+		}
+		if err := m.scanIssues(ctx, pending); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.confirmRun(issues, opts); err != nil {
+		return nil, err
+	}
+
+	var results []Result
+OUTER:
+	for _, i := range issues {
+		if i.IsPullRequest() {
+			continue
+		}
+		// the API filters on update time, older issues may have been updated since
+		if i.GetCreatedAt().Before(opts.Since) {
+			continue
+		}
+
+		for _, l := range i.Labels {
+			if *l.Name == m.cfg.SkipLabel || *l.Name == m.cfg.MigratedToLabel {
+				res := newResult(i, StatusSkipped)
+				m.report(res)
+				results = append(results, res)
+				continue OUTER
+			}
+		}
+		if reason := lowEngagement(i, opts); reason != "" {
+			m.printf("Skipping issue %d: %s\n", *i.Number, reason)
+			res := newResult(i, StatusSkipped)
+			res.Error = reason
+			m.report(res)
+			results = append(results, res)
+			continue
+		}
+		if opts.Export != nil && i.GetState() == "closed" {
+			if err := m.exportIssue(ctx, opts.Export, i); err != nil {
+				return results, err
+			}
+			res := newResult(i, StatusExported)
+			m.report(res)
+			results = append(results, res)
+			continue
+		}
+		if calls := m.Stats().APICalls; m.cfg.MaxAPICalls > 0 && calls >= m.cfg.MaxAPICalls {
+			m.printf("Stopping before issue %d, %d of %d API calls used\n", *i.Number, calls, m.cfg.MaxAPICalls)
+			return results, &APIBudgetError{Calls: calls, Next: *i.Number}
+		}
+		if opts.PreserveNumbers {
+			if err := m.burnNumbers(ctx, *i.Number); err != nil {
+				return results, err
+			}
+		}
+		res, err := m.migrateAndReport(ctx, i)
+		results = append(results, res)
+		if err != nil {
+			return results, err
+		}
+	}
+
+	// numbers are only known once every issue is migrated
+	if m.cfg.IncludeSubIssues && !m.cfg.NoTarget {
+		if err := m.linkSubIssues(ctx, results); err != nil {
+			return results, err
+		}
+	}
+	if m.cfg.IncludeDuplicates && !m.cfg.NoTarget {
+		if err := m.noteDuplicates(ctx, results); err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
+
+// listIssues returns every source issue in state updated at or after since,
+// newest first
+func (m *Migrator) listIssues(ctx context.Context, state string, since time.Time) ([]*github.Issue, error) {
+	from := m.cfg.From
+	opts := &github.IssueListByRepoOptions{
+		State:     state,
+		Since:     since,
+		Sort:      "created",
+		Direction: "desc",
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	var issues []*github.Issue
+	for {
+		page, resp, err := m.source.Issues.ListByRepo(ctx, from.Owner, from.Name, opts)
+		if err != nil {
+			return nil, newAPIError("list issues", err)
+		}
+		issues = append(issues, page...)
+		if resp.NextPage == 0 {
+			return issues, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// resumeFrom drops the issues a run processes before opts.ResumeFrom. Runs
+// go newest first unless they preserve numbers or order.
+func resumeFrom(issues []*github.Issue, opts AllOptions) []*github.Issue {
+	ascending := opts.PreserveNumbers || opts.PreserveOrder
+	var resumed []*github.Issue
+	for _, i := range issues {
+		if ascending && i.GetNumber() < opts.ResumeFrom || !ascending && i.GetNumber() > opts.ResumeFrom {
+			continue
+		}
+		resumed = append(resumed, i)
+	}
+	return resumed
+}
+
+// confirmRun prints the scope of a MigrateAll run and asks to start it
+func (m *Migrator) confirmRun(issues []*github.Issue, opts AllOptions) error {
+	var considered, labelled, exported int
+	for _, i := range issues {
+		if i.IsPullRequest() || i.GetCreatedAt().Before(opts.Since) {
+			continue
+		}
+		switch {
+		case m.willCreate(i, opts):
+			considered++
+		case opts.Export != nil && i.GetState() == "closed":
+			exported++
+		default:
+			labelled++
+		}
+	}
+	order := "newest first"
+	if opts.PreserveNumbers || opts.PreserveOrder {
+		order = "oldest first"
+	}
+
+	m.println("-------------------------------")
+	m.printf("Will consider %d issues from %s for migration to %s\n", considered, m.cfg.From, m.cfg.To)
+	m.printf("%d skipped by label, engagement or already migrated\n", labelled)
+	if opts.Export != nil {
+		m.printf("%d closed issues exported\n", exported)
+	}
+	m.printf("Issues are processed by creation date, %s\n", order)
+	if m.cfg.ConfirmTarget {
+		typed, err := m.cfg.Prompter.Input(fmt.Sprintf("Type %s to migrate into it", m.cfg.To), "")
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(typed) != m.cfg.To.String() {
+			m.println("Target repo did not match, not migrating")
+			return ErrUserAborted
+		}
+		return nil
+	}
+	if opts.Yes || m.cfg.AutoClean {
+		return nil
+	}
+	ok, err := m.cfg.Prompter.Confirm("Start migration")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrUserAborted
+	}
+	return nil
+}
+
+// confirmMigrate asks for the final confirmation of an issue. Issues with
+// internal terms require typing the ConfirmPhrase when one is configured.
+func (m *Migrator) confirmMigrate(issue *github.Issue, comments []*github.IssueComment) (bool, error) {
+	if m.cfg.AutoClean {
+		return true, nil
+	}
+	p := m.cfg.Prompter
+	if m.cfg.ConfirmPhrase == "" || !m.flaggedInternal(issue, comments) {
+		return p.Confirm("Migrate Resource?")
+	}
+	phrase := strings.ReplaceAll(m.cfg.ConfirmPhrase, "{number}", strconv.Itoa(issue.GetNumber()))
+	typed, err := p.Input(fmt.Sprintf("Internal terms were found in this issue. Type %q to migrate it", phrase), "")
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(typed) != phrase {
+		m.println("Confirmation phrase did not match, not migrating")
+		return false, nil
+	}
+	return true, nil
+}
+
+// flaggedInternal reports whether the source title, body or any comment holds an internal term
+func (m *Migrator) flaggedInternal(issue *github.Issue, comments []*github.IssueComment) bool {
+	if m.scanForInternal(issue.GetTitle()) || m.scanForInternal(issue.GetBody()) {
+		return true
+	}
+	for _, c := range comments {
+		if m.scanForInternal(c.GetBody()) {
+			return true
+		}
+	}
+	return false
+}
+
+// migrateAndReport migrates a single issue and reports the outcome, including failures
+func (m *Migrator) migrateAndReport(ctx context.Context, issue *github.Issue) (Result, error) {
+	start := time.Now()
+	m.emit(EventStarted, newResult(issue, ""))
+	res, err := m.migrateWithRetry(ctx, issue)
+	m.stats.Issues++
+	m.stats.IssueTime += time.Since(start)
+	if err != nil {
+		res = newResult(issue, StatusFailed)
+		res.Error = err.Error()
+	}
+	m.report(res)
+	return res, err
+}
+
+func (m *Migrator) migrateOne(ctx context.Context, issue *github.Issue) (Result, error) {
+	from, to, p := m.cfg.From, m.cfg.To, m.cfg.Prompter
+	res := newResult(issue, StatusDeclined)
+	c, err := m.issueComments(ctx, *issue.Number)
+	if err != nil {
+		return res, err
+	}
+	if reason := m.securityReason(issue, c); reason != "" && !m.cfg.AllowSecurity {
+		m.printf("Issue %d %s, refusing to migrate it\n", *issue.Number, reason)
+		res.Status = StatusSecurity
+		res.Error = reason
+		return res, nil
+	}
+	if existing, ok := m.migrated[*issue.Number]; ok {
+		m.printf("Issue %d was already migrated to %s, completing the source issue\n", *issue.Number, existing.GetHTMLURL())
+		res.Status = StatusCompleted
+		res.Dest = existing.GetNumber()
+		res.DestURL = existing.GetHTMLURL()
+		return res, m.completeSource(ctx, issue, c, existing.GetHTMLURL())
+	}
+	// the marker records the source as fetched, so drift can tell when it changes
+	prov := provenance{
+		Repo:   from.String(),
+		Number: *issue.Number,
+		Title:  hashText(issue.GetTitle()),
+		Body:   hashText(issue.GetBody()),
+	}
+	if issue, c, res.PrivateAssets, err = m.prepareContent(issue, c); err != nil {
+		m.printf("Issue %d failed its transform: %v\n", *issue.Number, err)
+		res.Status = StatusFailed
+		res.Error = err.Error()
+		return res, nil
+	}
+	promoted := false
+	if m.cfg.FirstCommentAsBody {
+		if p, rest := m.promoteFirstComment(issue, c); p != issue {
+			m.printf("Issue %d has a short body, using its first comment as the body\n", *issue.Number)
+			issue, c, promoted = p, rest, true
+		}
+	}
+	if m.cfg.SyncAssignees && !m.cfg.NoTarget && m.cfg.OnAssigneeError != AssigneeErrorDrop {
+		logins, err := m.unassignable(ctx, issue)
+		if err != nil {
+			return res, err
+		}
+		if len(logins) > 0 {
+			reason := fmt.Sprintf("assignees %s can not be assigned in %s", strings.Join(logins, ", "), to)
+			if m.cfg.OnAssigneeError == AssigneeErrorFail {
+				return res, fmt.Errorf("%w: issue %d: %s", ErrUnassignable, *issue.Number, reason)
+			}
+			m.printf("Skipping issue %d: %s\n", *issue.Number, reason)
+			res.Status = StatusSkipped
+			res.Error = reason
+			return res, nil
+		}
+	}
+	if m.cfg.AutoClean {
+		reason, err := m.reviewReason(ctx, issue, c)
+		if err != nil {
+			return res, err
+		}
+		if reason != "" {
+			m.printf("Issue %d %s, leaving it for manual review\n", *issue.Number, reason)
+			res.Status = StatusReview
+			res.Error = reason
+			return res, nil
+		}
+	}
+
+	m.println("-------------------------------")
+	m.printf("Migrating Issue %d\nTitle: %q\nBody: %q\nURL: %s\n\n", *issue.Number, *issue.Title, issue.GetBody(), *issue.HTMLURL)
+	if summary := m.internalSummary(issue, c); summary != "" {
+		if m.cfg.Color {
+			summary = "\x1b[33m" + summary + "\x1b[0m"
+		}
+		m.printf("⚠ %s\n\n", summary)
+	}
+	if m.cfg.Browse && !m.cfg.AutoClean {
+		browse, err := p.Confirm("Open in browser?")
+		if err != nil {
+			return res, err
+		}
+		if browse {
+			if err := openBrowser(issue.GetHTMLURL()); err != nil {
+				m.printf("Could not open browser: %v\n", err)
+			}
+		}
+	}
+
+	// Import?
+	if !m.cfg.AutoClean {
+		importIssue, err := p.Confirm("Import Issue?")
+		if err != nil || !importIssue {
+			return res, err
+		}
+	}
+
+	var req *github.IssueRequest
+	switch {
+	case m.cfg.AutoClean:
+		req, err = m.generateAutoRequest(ctx, issue, c)
+	case m.cfg.CombinedEdit:
+		req, err = m.generateCombinedRequest(ctx, issue, c)
+	default:
+		req, err = m.generateIssueRequest(ctx, issue, c)
+	}
+	if err != nil {
+		return res, err
+	}
+	// synced is the prepared source body as it starts the reviewed body,
+	// at syncedAt, or -1 when a reviewer edited it
+	synced, err := m.normalizeText(ctx, issue.GetBody())
+	if err != nil {
+		return res, err
+	}
+	syncedAt := -1
+	if !promoted && strings.HasPrefix(req.GetBody(), synced) {
+		syncedAt = 0
+	}
+	if len(m.cfg.AddLabels) > 0 {
+		var labels []string
+		if req.Labels != nil {
+			labels = *req.Labels
+		}
+		labels = m.withAddLabels(labels)
+		req.Labels = &labels
+	}
+	if m.cfg.CreateAsDraft {
+		var labels []string
+		if req.Labels != nil {
+			labels = *req.Labels
+		}
+		labels = append(labels, m.cfg.PendingReviewLabel)
+		req.Labels = &labels
+	}
+	if m.cfg.RequireLabel != "" || len(m.cfg.RequireAnyOf) > 0 {
+		var labels []string
+		if req.Labels != nil {
+			labels = *req.Labels
+		}
+		labels, added, reason := m.requireLabels(labels)
+		if reason != "" {
+			m.printf("Issue %d fails the label policy: %s\n", *issue.Number, reason)
+			res.Status = StatusFailed
+			res.Error = reason
+			return res, nil
+		}
+		if len(added) > 0 {
+			m.printf("Adding required labels %s to issue %d\n", strings.Join(added, ", "), *issue.Number)
+			res.EnforcedLabels = added
+		}
+		req.Labels = &labels
+	}
+	if !m.cfg.NoTarget {
+		res.DroppedLabels, res.LabelCollisions, err = m.ensureLabels(ctx, issue.Labels, req)
+		if err != nil {
+			return res, err
+		}
+	}
+	if !m.cfg.NoTarget && (m.cfg.SyncAssignees || m.cfg.ExpandTeams || m.cfg.AssigneeFallback != "") {
+		assignees, note, err := m.mapAssignees(ctx, issue)
+		if err != nil {
+			return res, err
+		}
+		req.Assignees = &assignees
+		if note != "" {
+			teamBody := req.GetBody() + note
+			req.Body = &teamBody
+		}
+	}
+	if m.template != nil {
+		templatedBody := m.template.Body + "\n\n" + req.GetBody()
+		if syncedAt >= 0 {
+			syncedAt += len(templatedBody) - len(req.GetBody())
+		}
+		req.Body = &templatedBody
+	} else if len(m.templates) > 0 && !matchesTemplate(m.templates, req.GetBody()) {
+		m.printf("Warning: issue %d does not follow any issue template of %s, see --target-template\n", *issue.Number, to)
+	}
+	if m.preamble != nil {
+		preamble, err := m.renderPreamble(issue)
+		if err != nil {
+			return res, err
+		}
+		preambleBody := preamble + "\n\n" + req.GetBody()
+		if syncedAt >= 0 {
+			syncedAt += len(preambleBody) - len(req.GetBody())
+		}
+		req.Body = &preambleBody
+	}
+	if m.milestoneNumber != 0 {
+		req.Milestone = &m.milestoneNumber
+	}
+	if m.cfg.IncludeResolution {
+		refs, err := m.resolutionRefs(ctx, issue)
+		if err != nil {
+			return res, err
+		}
+		if len(refs) > 0 {
+			resolvedBody := req.GetBody() + "\n"
+			for _, r := range refs {
+				resolvedBody = resolvedBody + "\nOriginally resolved by " + r
+			}
+			req.Body = &resolvedBody
+		}
+	}
+	if m.cfg.IncludeDevRefs {
+		refs, err := m.developmentRefs(ctx, issue)
+		if err != nil {
+			return res, err
+		}
+		if len(refs) > 0 {
+			devBody := req.GetBody() + "\n\nOriginal development refs: " + strings.Join(refs, ", ")
+			req.Body = &devBody
+		}
+	}
+	if m.cfg.IncludeLabelHistory {
+		history, err := m.labelHistory(ctx, issue, m.cfg.LabelHistoryLimit)
+		if err != nil {
+			return res, err
+		}
+		if len(history) > 0 {
+			historyBody := req.GetBody() + labelHistoryNote(history)
+			req.Body = &historyBody
+		}
+	}
+	if m.cfg.IncludeParticipants {
+		if logins := participants(issue, c); len(logins) > 0 {
+			participantsBody := req.GetBody() + participantsNote(logins)
+			req.Body = &participantsBody
+		}
+	}
+	var typeID string
+	if m.cfg.IncludeTypes {
+		name, err := m.sourceIssueType(ctx, issue)
+		if err != nil {
+			return res, err
+		}
+		if id, ok := m.issueTypes[strings.ToLower(name)]; ok {
+			typeID = id
+		} else if name != "" {
+			if m.issueTypes != nil {
+				m.printf("Issue type %q does not exist in %s, recording it in the body\n", name, to)
+			}
+			typedBody := req.GetBody() + "\n\nType: " + name
+			req.Body = &typedBody
+		}
+	}
+	fitted, overflow := m.fitBody(issue, req.GetBody())
+	if end := syncedAt + len(synced); syncedAt >= 0 && end <= len(fitted) && fitted[syncedAt:end] == synced {
+		prov.Start, prov.End, prov.Synced = syncedAt, end, hashText(synced)
+	}
+	markedBody := fitted + "\n\n" + prov.marker()
+	req.Body = &markedBody
+
+	confirmed, err := m.confirmMigrate(issue, c)
+	if err != nil || !confirmed {
+		return res, err
+	}
+	if m.cfg.NoTarget {
+		if err := m.writeArchive(issue, req, ""); err != nil {
+			return res, err
+		}
+		res.Status = StatusArchived
+		return res, nil
+	}
+	// resolved only once confirmed, so a declined issue leaves no milestone
+	// created for it behind
+	if m.cfg.SyncMilestones && issue.Milestone != nil && req.Milestone == nil {
+		number, err := m.ensureMilestone(ctx, issue.Milestone)
+		if err != nil {
+			return res, err
+		}
+		req.Milestone = &number
+	}
+
+	var newIssue *github.Issue
+	err = m.withSecondaryRetry(ctx, func() (err error) {
+		newIssue, _, err = m.client.Issues.Create(ctx, to.Owner, to.Name, req)
+		return err
+	})
+	if err != nil {
+		return res, newAPIError("create issue", err)
+	}
+	if m.preserveNumbers && newIssue.GetNumber() != issue.GetNumber() {
+		m.printf("WARNING: issue %d was created as %d in the target, its number was not preserved\n", issue.GetNumber(), newIssue.GetNumber())
+		res.NumberNotPreserved = true
+	}
+	created := res
+	created.Dest = newIssue.GetNumber()
+	created.DestURL = newIssue.GetHTMLURL()
+	m.emit(EventCreated, created)
+	if typeID != "" {
+		if err := m.setIssueType(ctx, newIssue, typeID); err != nil {
+			return res, err
+		}
+	}
+	m.pinIssue(ctx, *issue.Number, newIssue)
+	if m.cfg.ReplicateReactions {
+		if err := m.replicateReactions(ctx, issue, newIssue); err != nil {
+			return res, err
+		}
+	}
+	for _, chunk := range overflow {
+		chunk := chunk
+		err = m.withSecondaryRetry(ctx, func() (err error) {
+			_, _, err = m.client.Issues.CreateComment(ctx, to.Owner, to.Name, *newIssue.Number, &github.IssueComment{Body: &chunk})
+			return err
+		})
+		if err != nil {
+			return res, newAPIError("create overflow comment", err)
+		}
+	}
+	if m.cfg.SourceComment {
+		body := fmt.Sprintf("Migrated from %s on %s", issue.GetHTMLURL(), time.Now().Format("2006-01-02"))
+		err = m.withSecondaryRetry(ctx, func() (err error) {
+			_, _, err = m.client.Issues.CreateComment(ctx, to.Owner, to.Name, *newIssue.Number, &github.IssueComment{Body: &body})
+			return err
+		})
+		if err != nil {
+			return res, newAPIError("create source comment", err)
+		}
+	}
+	if m.cfg.CreateAsDraft {
+		// a closed source is closed below with its own reason, so only open
+		// ones are closed here, before the note describes them as closed
+		note := fmt.Sprintf("Labelled %s until reviewed. Remove the label once this issue has been verified.", m.cfg.PendingReviewLabel)
+		if issue.GetState() != "closed" {
+			if err := m.closeIssue(ctx, *newIssue.Number, StateReasonNotPlanned); err != nil {
+				return res, newAPIError("close issue", err)
+			}
+			note = fmt.Sprintf("Closed and labelled %s until reviewed. Reopen this issue once it has been verified.", m.cfg.PendingReviewLabel)
+		}
+		err = m.withSecondaryRetry(ctx, func() (err error) {
+			_, _, err = m.client.Issues.CreateComment(ctx, to.Owner, to.Name, *newIssue.Number, &github.IssueComment{Body: &note})
+			return err
+		})
+		if err != nil {
+			return res, newAPIError("create review comment", err)
+		}
+	}
+	if issue.GetState() == "closed" {
+		reason, err := m.sourceStateReason(ctx, issue)
+		if err != nil {
+			return res, err
+		}
+		if err := m.closeIssue(ctx, *newIssue.Number, reason); err != nil {
+			return res, newAPIError("close issue", err)
+		}
+	}
+	finalIssue, _, err := m.client.Issues.Get(ctx, to.Owner, to.Name, *newIssue.Number)
+	if err != nil {
+		return res, newAPIError("get issue", err)
+	}
+
+	if err := m.completeSource(ctx, issue, c, *finalIssue.HTMLURL); err != nil {
+		return res, err
+	}
+	if m.cfg.MarkdownDir != "" {
+		if err := m.writeArchive(issue, req, finalIssue.GetHTMLURL()); err != nil {
+			return res, err
+		}
+	}
+
+	res.Status = StatusMigrated
+	res.Dest = finalIssue.GetNumber()
+	res.DestURL = finalIssue.GetHTMLURL()
+	return res, nil
+}
@@ -0,0 +1,53 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v36/github"
+)
+
+func TestMigrateAllPreservesOrderAcrossPages(t *testing.T) {
+	f := newFakeGitHub(t)
+	const count = 130
+	for i := 1; i <= count; i++ {
+		f.addIssue(testSource, &github.Issue{Title: github.String(fmt.Sprintf("Issue %d", i))})
+	}
+
+	results, err := newTestMigrator(t, f, Config{}).MigrateAll(context.Background(), AllOptions{PreserveOrder: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != count {
+		t.Fatalf("migrated %d issues, want %d", len(results), count)
+	}
+	for i, issue := range f.repo(testTarget).issues {
+		if want := fmt.Sprintf("Issue %d", i+1); issue.GetTitle() != want {
+			t.Fatalf("target issue %d is %q, want %q", issue.GetNumber(), issue.GetTitle(), want)
+		}
+	}
+}
+
+func TestMigrateAllNullBody(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.addIssue(testSource, &github.Issue{Title: github.String("Created by an integration")})
+	f.addIssue(testSource, &github.Issue{Title: github.String("Described in a comment")})
+	f.repo(testSource).comments[2] = []*github.IssueComment{{Body: github.String("The actual description")}}
+
+	results, err := newTestMigrator(t, f, Config{FirstCommentAsBody: true}).MigrateAll(context.Background(), AllOptions{PreserveOrder: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("migrated %d issues, want 2", len(results))
+	}
+	target := f.repo(testTarget).issues
+	if len(target) != 2 {
+		t.Fatalf("created %d target issues, want 2", len(target))
+	}
+	if body := target[1].GetBody(); !strings.Contains(body, "The actual description") {
+		t.Errorf("target issue 2 has body %q, want the first comment promoted", body)
+	}
+}
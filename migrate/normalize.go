@@ -0,0 +1,99 @@
+package migrate
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// Emoji modes for Config.Emoji
+const (
+	EmojiKeep  = "keep"
+	EmojiStrip = "strip"
+)
+
+var (
+	teamMentionRe    = regexp.MustCompile(`(^|[^\w@])@([A-Za-z0-9][A-Za-z0-9-]*)/([A-Za-z0-9][A-Za-z0-9_.-]*)`)
+	emojiShortcodeRe = regexp.MustCompile(`(^|[^\w:]):([a-z0-9_+-]+):`)
+)
+
+// normalizeText prepares text for the target: team mentions that do not
+// resolve there lose their @, and emoji shortcodes are stripped if configured.
+// Fenced code blocks are left as they are.
+func (m *Migrator) normalizeText(ctx context.Context, s string) (string, error) {
+	var lookupErr error
+	s = mapProse(s, func(prose string) string {
+		return m.normalizeProse(ctx, prose, &lookupErr)
+	})
+	if lookupErr != nil {
+		return "", lookupErr
+	}
+	return s, nil
+}
+
+// normalizeProse normalizes markdown outside of code blocks, recording the
+// first failed team lookup in lookupErr
+func (m *Migrator) normalizeProse(ctx context.Context, s string, lookupErr *error) string {
+	s = teamMentionRe.ReplaceAllStringFunc(s, func(match string) string {
+		sub := teamMentionRe.FindStringSubmatch(match)
+		prefix, org, slug := sub[1], sub[2], sub[3]
+		ok, err := m.teamResolves(ctx, org, slug)
+		if err != nil && *lookupErr == nil {
+			*lookupErr = err
+		}
+		if ok || err != nil {
+			return match
+		}
+		return prefix + org + "/" + slug
+	})
+
+	if m.cfg.Emoji == EmojiStrip {
+		// adjacent shortcodes share a colon, repeat until none are left
+		for {
+			stripped := emojiShortcodeRe.ReplaceAllString(s, "$1")
+			if stripped == s {
+				break
+			}
+			s = stripped
+		}
+	}
+	return s
+}
+
+// teamResolves reports whether @org/slug mentions a team of the target org
+func (m *Migrator) teamResolves(ctx context.Context, org, slug string) (bool, error) {
+	if !strings.EqualFold(org, m.cfg.To.Owner) {
+		return false, nil
+	}
+	key := strings.ToLower(slug)
+	if ok, seen := m.teams[key]; seen {
+		return ok, nil
+	}
+	_, resp, err := m.client.Teams.GetTeamBySlug(ctx, m.cfg.To.Owner, slug)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return false, newAPIError("get team", err)
+	}
+	if m.teams == nil {
+		m.teams = map[string]bool{}
+	}
+	m.teams[key] = err == nil
+	return err == nil, nil
+}
+
+// normalizeRequest applies normalizeText to the title and body of req
+func (m *Migrator) normalizeRequest(ctx context.Context, req *github.IssueRequest) error {
+	title, err := m.normalizeText(ctx, req.GetTitle())
+	if err != nil {
+		return err
+	}
+	body, err := m.normalizeText(ctx, req.GetBody())
+	if err != nil {
+		return err
+	}
+	req.Title = &title
+	req.Body = &body
+	return nil
+}
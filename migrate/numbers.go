@@ -0,0 +1,120 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// nextIssueNumber returns the number GitHub will assign to the next issue created in the target
+func (m *Migrator) nextIssueNumber(ctx context.Context) (int, error) {
+	to := m.cfg.To
+	// pull requests share the issue number sequence and are included in this listing
+	latest, _, err := m.client.Issues.ListByRepo(ctx, to.Owner, to.Name, &github.IssueListByRepoOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 1,
+		},
+		State:     "all",
+		Sort:      "created",
+		Direction: "desc",
+	})
+	if err != nil {
+		return 0, newAPIError("list issues", err)
+	}
+	if len(latest) == 0 {
+		return 1, nil
+	}
+	return *latest[0].Number + 1, nil
+}
+
+// burnNumbers creates closed placeholder issues in the target until the next issue created will be number
+func (m *Migrator) burnNumbers(ctx context.Context, number int) error {
+	to := m.cfg.To
+	next, err := m.nextIssueNumber(ctx)
+	if err != nil {
+		return err
+	}
+	if next > number {
+		m.printf("WARNING: can not preserve number %d, the target is already at %d\n", number, next)
+		return nil
+	}
+
+	title := "Placeholder"
+	body := "This issue was created by migratron to preserve issue numbering and can be ignored."
+	closed := "closed"
+	// next follows the numbers the placeholders get, which differ from the
+	// guess when an issue is created meanwhile or the guess missed deleted
+	// or transferred issues
+	for next < number {
+		var placeholder *github.Issue
+		err := m.withSecondaryRetry(ctx, func() (err error) {
+			placeholder, _, err = m.client.Issues.Create(ctx, to.Owner, to.Name, &github.IssueRequest{
+				Title: &title,
+				Body:  &body,
+			})
+			return err
+		})
+		if err != nil {
+			return newAPIError("create placeholder issue", err)
+		}
+		err = m.withSecondaryRetry(ctx, func() (err error) {
+			_, _, err = m.client.Issues.Edit(ctx, to.Owner, to.Name, *placeholder.Number, &github.IssueRequest{State: &closed})
+			return err
+		})
+		if err != nil {
+			return newAPIError("close placeholder issue", err)
+		}
+		m.printf("placeholder: %d\n", *placeholder.Number)
+		next = placeholder.GetNumber() + 1
+	}
+	if next > number {
+		m.printf("WARNING: can not preserve number %d, the target is already at %d\n", number, next)
+	}
+
+	return nil
+}
+
+// ensureMilestone returns the number of the target milestone with the title
+// of src, open or closed, creating it with the state, due date and
+// description of src if needed
+func (m *Migrator) ensureMilestone(ctx context.Context, src *github.Milestone) (int, error) {
+	to := m.cfg.To
+	if m.milestones == nil {
+		m.milestones = map[string]int{}
+		opts := &github.MilestoneListOptions{
+			State: "all",
+			ListOptions: github.ListOptions{
+				PerPage: 100,
+			},
+		}
+		for {
+			milestones, resp, err := m.client.Issues.ListMilestones(ctx, to.Owner, to.Name, opts)
+			if err != nil {
+				m.milestones = nil
+				return 0, newAPIError("list milestones", err)
+			}
+			for _, ms := range milestones {
+				m.milestones[ms.GetTitle()] = ms.GetNumber()
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+	if number, ok := m.milestones[src.GetTitle()]; ok {
+		return number, nil
+	}
+
+	ms, _, err := m.client.Issues.CreateMilestone(ctx, to.Owner, to.Name, &github.Milestone{
+		Title:       src.Title,
+		State:       src.State,
+		Description: src.Description,
+		DueOn:       src.DueOn,
+	})
+	if err != nil {
+		return 0, newAPIError("create milestone", err)
+	}
+	m.milestones[ms.GetTitle()] = ms.GetNumber()
+	return ms.GetNumber(), nil
+}
@@ -0,0 +1,100 @@
+package migrate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v36/github"
+)
+
+func TestSyncMilestonesCreatesClosedMilestoneOnce(t *testing.T) {
+	f := newFakeGitHub(t)
+	due := time.Date(2021, 6, 30, 7, 0, 0, 0, time.UTC)
+	milestone := &github.Milestone{
+		Title:       github.String("v1.0"),
+		State:       github.String("closed"),
+		Description: github.String("First stable release"),
+		DueOn:       &due,
+	}
+	f.repo(testTarget).milestones = []*github.Milestone{{
+		Number: github.Int(1),
+		Title:  github.String("v0.9"),
+		State:  github.String("open"),
+	}}
+	for _, title := range []string{"Crash on start", "Slow startup"} {
+		f.addIssue(testSource, &github.Issue{Title: github.String(title), Milestone: milestone})
+	}
+
+	m := newTestMigrator(t, f, Config{SyncMilestones: true})
+	results, err := m.MigrateAll(context.Background(), AllOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, res := range results {
+		if res.Status != StatusMigrated {
+			t.Fatalf("issue %d %s, want %s", res.Source, res.Status, StatusMigrated)
+		}
+	}
+	if len(results) != 2 {
+		t.Fatalf("migrated %d issues, want 2", len(results))
+	}
+
+	target := f.repo(testTarget)
+	if len(target.milestones) != 2 {
+		t.Fatalf("target has %d milestones, want 2", len(target.milestones))
+	}
+	created := target.milestones[1]
+	if created.GetTitle() != "v1.0" || created.GetState() != "closed" || created.GetDescription() != "First stable release" || !created.GetDueOn().Equal(due) {
+		t.Errorf("created milestone %q %s %q due %s, want %q closed %q due %s",
+			created.GetTitle(), created.GetState(), created.GetDescription(), created.GetDueOn(), "v1.0", "First stable release", due)
+	}
+	for _, issue := range target.issues {
+		if got := issue.GetMilestone().GetNumber(); got != created.GetNumber() {
+			t.Errorf("target issue %d is in milestone %d, want %d", issue.GetNumber(), got, created.GetNumber())
+		}
+	}
+}
+
+// decliner approves every prompt but the final confirmation of an issue
+type decliner struct{ approver }
+
+func (decliner) Confirm(label string) (bool, error) { return label != "Migrate Resource?", nil }
+
+func TestSyncMilestonesDeclinedIssueCreatesNoMilestone(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.addIssue(testSource, &github.Issue{
+		Title:     github.String("Crash on start"),
+		Milestone: &github.Milestone{Title: github.String("v1.0"), State: github.String("open")},
+	})
+
+	m := newTestMigrator(t, f, Config{SyncMilestones: true, Prompter: decliner{}})
+	if _, err := m.MigrateIssue(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	target := f.repo(testTarget)
+	if len(target.issues) != 0 || len(target.milestones) != 0 {
+		t.Errorf("declined issue left %d issues and %d milestones in the target, want none", len(target.issues), len(target.milestones))
+	}
+}
+
+func TestBurnNumbersFollowsPlaceholderNumbers(t *testing.T) {
+	f := newFakeGitHub(t)
+	// issue 2 was transferred in and keeps its older creation time, so the
+	// newest created issue is not the highest numbered one
+	for _, created := range []time.Time{
+		time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		created := created
+		f.addIssue(testTarget, &github.Issue{Title: github.String("Existing"), CreatedAt: &created})
+	}
+
+	m := newTestMigrator(t, f, Config{})
+	if err := m.burnNumbers(context.Background(), 4); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(f.repo(testTarget).issues); n != 3 {
+		t.Errorf("target has %d issues, want 3 so the next one is number 4", n)
+	}
+}
@@ -0,0 +1,90 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// Policies for Config.Overflow
+const (
+	OverflowTruncate = "truncate"
+	OverflowComments = "comments"
+)
+
+// DefaultMaxBodyLength is the most characters GitHub accepts in an issue body or comment
+const DefaultMaxBodyLength = 65536
+
+// bodyReserve is kept free at the end of a body for the note and provenance marker
+const bodyReserve = 512
+
+// collatedStart and collatedEnd delimit the collated comments in a body
+// until fitBody removes them, so only they are cut when it is too long
+const (
+	collatedStart = "<!-- migratron:collated -->"
+	collatedEnd   = "<!-- /migratron:collated -->"
+)
+
+// fitBody shortens a body that would exceed MaxBodyLength. Only the collated
+// comments are cut, unless the body is too long without them. It returns the
+// body to create the issue with and, for OverflowComments, the overflow
+// split into chunks to post as follow-up comments.
+func (m *Migrator) fitBody(issue *github.Issue, body string) (string, []string) {
+	limit := m.cfg.MaxBodyLength - bodyReserve
+	before, collated, after := splitCollated(body)
+	body = before + collated + after
+	size := len([]rune(body))
+	if size <= limit {
+		return body, nil
+	}
+
+	truncated := fmt.Sprintf("\n\n_...truncated, see source %s_", issue.GetHTMLURL())
+	runes := []rune(collated)
+	keep := len(runes) - (size - limit)
+	if m.cfg.Overflow == OverflowComments {
+		// the note is short, and bodyReserve leaves room for it
+		if keep >= 0 {
+			m.printf("Warning: issue %d body is %d characters, moving the overflow of its collated comments into follow-up comments\n", issue.GetNumber(), size)
+			chunks := chunk(runes[keep:], limit)
+			note := fmt.Sprintf("\n\n_continued in %d comments below_", len(chunks))
+			return closeFence(before+string(runes[:keep])) + note + after, chunks
+		}
+		m.printf("Warning: issue %d body is %d characters without its collated comments, moving the overflow into follow-up comments\n", issue.GetNumber(), size)
+		all := []rune(body)
+		chunks := chunk(all[limit:], limit)
+		return closeFence(string(all[:limit])) + fmt.Sprintf("\n\n_continued in %d comments below_", len(chunks)), chunks
+	}
+
+	if keep >= 0 {
+		m.printf("Warning: issue %d body is %d characters, truncating its collated comments\n", issue.GetNumber(), size)
+		return closeFence(before+string(runes[:keep])) + truncated + after, nil
+	}
+	m.printf("Warning: issue %d body is %d characters without its collated comments, truncating it to %d\n", issue.GetNumber(), size, limit)
+	return closeFence(string([]rune(body)[:limit])) + truncated, nil
+}
+
+// splitCollated splits a body around its delimited collated comments,
+// dropping the delimiters. A body without them is all before.
+func splitCollated(body string) (before, collated, after string) {
+	start := strings.Index(body, collatedStart)
+	end := strings.LastIndex(body, collatedEnd)
+	if start < 0 || end < start {
+		return body, "", ""
+	}
+	return body[:start], body[start+len(collatedStart) : end], body[end+len(collatedEnd):]
+}
+
+// chunk splits runes into strings of at most size runes
+func chunk(runes []rune, size int) []string {
+	var chunks []string
+	for len(runes) > 0 {
+		n := len(runes)
+		if n > size {
+			n = size
+		}
+		chunks = append(chunks, string(runes[:n]))
+		runes = runes[n:]
+	}
+	return chunks
+}
@@ -0,0 +1,30 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v36/github"
+)
+
+func TestFitBodyClosesCutFence(t *testing.T) {
+	m := New(Config{MaxBodyLength: bodyReserve + 200})
+	issue := &github.Issue{Number: github.Int(7), HTMLURL: github.String("https://github.com/acme/internal/issues/7")}
+	trace := "```\n" + strings.Repeat("at main.run(main.go:42)\n", 20) + "```\n"
+	body := "It panics.\n" + collatedStart + "\n" + trace + collatedEnd + "\n\nOriginally resolved by #8"
+
+	fitted, overflow := m.fitBody(issue, body)
+	if overflow != nil {
+		t.Errorf("fitBody returned overflow %q, want none when truncating", overflow)
+	}
+	note := strings.Index(fitted, "_...truncated, see source")
+	if note < 0 {
+		t.Fatalf("fitBody(%q) = %q, want a truncation note", body, fitted)
+	}
+	if _, _, open := splitFenced(fitted[:note]); open {
+		t.Errorf("fitBody(%q) = %q, leaving the code block open before the note", body, fitted)
+	}
+	if !strings.HasSuffix(fitted, "Originally resolved by #8") {
+		t.Errorf("fitBody(%q) = %q, want the text after the collated comments kept", body, fitted)
+	}
+}
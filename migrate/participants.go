@@ -0,0 +1,43 @@
+package migrate
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// participants returns the sorted, distinct logins of the reporter,
+// assignees and commenters of a source issue
+func participants(issue *github.Issue, comments []*github.IssueComment) []string {
+	seen := map[string]bool{}
+	var logins []string
+	add := func(u *github.User) {
+		login := u.GetLogin()
+		if login == "" || seen[strings.ToLower(login)] {
+			return
+		}
+		seen[strings.ToLower(login)] = true
+		logins = append(logins, login)
+	}
+	add(issue.GetUser())
+	for _, a := range issue.Assignees {
+		add(a)
+	}
+	for _, c := range comments {
+		add(c.GetUser())
+	}
+	sort.Slice(logins, func(a, b int) bool {
+		return strings.ToLower(logins[a]) < strings.ToLower(logins[b])
+	})
+	return logins
+}
+
+// participantsNote lists logins as code spans, so they do not notify anyone
+func participantsNote(logins []string) string {
+	quoted := make([]string, len(logins))
+	for i, l := range logins {
+		quoted[i] = "`@" + l + "`"
+	}
+	return "\n\nOriginal participants: " + strings.Join(quoted, ", ")
+}
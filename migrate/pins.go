@@ -0,0 +1,75 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// maxPinnedIssues is how many issues GitHub lets a repo pin
+const maxPinnedIssues = 3
+
+// pinnedIssues returns the numbers of the issues pinned in repo
+func (m *Migrator) pinnedIssues(ctx context.Context, repo Repo) (map[int]bool, error) {
+	var data struct {
+		Repository struct {
+			PinnedIssues struct {
+				Nodes []struct {
+					Issue struct {
+						Number int `json:"number"`
+					} `json:"issue"`
+				} `json:"nodes"`
+			} `json:"pinnedIssues"`
+		} `json:"repository"`
+	}
+	err := m.graphql(ctx, m.clientFor(repo), `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    pinnedIssues(first: 3) { nodes { issue { number } } }
+  }
+}`, map[string]interface{}{"owner": repo.Owner, "name": repo.Name}, &data)
+	if err != nil {
+		return nil, newAPIError("list pinned issues", err)
+	}
+	pinned := map[int]bool{}
+	for _, n := range data.Repository.PinnedIssues.Nodes {
+		pinned[n.Issue.Number] = true
+	}
+	return pinned, nil
+}
+
+// loadPins records the pinned source issues and how many target issues are
+// already pinned. Pins are not preserved if either can not be read.
+func (m *Migrator) loadPins(ctx context.Context) {
+	m.sourcePins, m.targetPinned = nil, 0
+	source, err := m.pinnedIssues(ctx, m.cfg.From)
+	if err != nil {
+		m.printf("Warning: pins will not be preserved, %v\n", err)
+		return
+	}
+	target, err := m.pinnedIssues(ctx, m.cfg.To)
+	if err != nil {
+		m.printf("Warning: pins will not be preserved, %v\n", err)
+		return
+	}
+	m.sourcePins, m.targetPinned = source, len(target)
+}
+
+// pinIssue pins a target issue migrated from a pinned source issue. It only
+// warns when the pin limit is reached or the token may not pin.
+func (m *Migrator) pinIssue(ctx context.Context, source int, issue *github.Issue) {
+	if !m.sourcePins[source] {
+		return
+	}
+	if m.targetPinned >= maxPinnedIssues {
+		m.printf("Warning: %s already has %d pinned issues, not pinning issue %d\n", m.cfg.To, maxPinnedIssues, issue.GetNumber())
+		return
+	}
+	err := m.graphql(ctx, m.client, `mutation($issue: ID!) {
+  pinIssue(input: {issueId: $issue}) { issue { id } }
+}`, map[string]interface{}{"issue": issue.GetNodeID()}, nil)
+	if err != nil {
+		m.printf("Warning: could not pin issue %d: %v\n", issue.GetNumber(), err)
+		return
+	}
+	m.targetPinned++
+}
@@ -0,0 +1,186 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// Actions of a PlannedIssue
+const (
+	PlanMigrate  = "migrate"
+	PlanComplete = "complete"
+	PlanSkip     = "skip"
+	PlanExport   = "export"
+	PlanSecurity = "security"
+	PlanFail     = "fail"
+)
+
+// PlannedIssue describes what a run would do with one source issue. Fields
+// are declared in key order and hold nothing time dependent, so plans of two
+// dry runs can be diffed line by line.
+type PlannedIssue struct {
+	Action        string   `json:"action"`
+	Comments      int      `json:"comments"`
+	DroppedLabels []string `json:"dropped_labels,omitempty"`
+	Internal      []string `json:"internal,omitempty"`
+	Labels        []string `json:"labels,omitempty"`
+	Milestone     string   `json:"milestone,omitempty"`
+	Reason        string   `json:"reason,omitempty"`
+	Source        int      `json:"source"`
+	State         string   `json:"state"`
+	Title         string   `json:"title"`
+}
+
+// writePlan writes one PlannedIssue per line to w for every issue a run
+// would consider, ordered by source number. Nothing is prompted or changed.
+func (m *Migrator) writePlan(ctx context.Context, w io.Writer, issues []*github.Issue, opts AllOptions) error {
+	if !m.cfg.NoTarget {
+		var err error
+		if m.migrated, err = m.indexMigrated(ctx); err != nil {
+			return err
+		}
+	}
+	if opts.PlanLabels {
+		if err := m.loadTargetLabels(ctx); err != nil {
+			return err
+		}
+	}
+
+	var plans []PlannedIssue
+	for _, i := range issues {
+		if i.IsPullRequest() || i.GetCreatedAt().Before(opts.Since) {
+			continue
+		}
+		p, err := m.planIssue(ctx, i, opts)
+		if err != nil {
+			return err
+		}
+		if opts.PlanLabels && p.Action == PlanMigrate {
+			if err := m.planLabels(ctx, i, &p); err != nil {
+				return err
+			}
+		}
+		plans = append(plans, p)
+	}
+	sort.Slice(plans, func(a, b int) bool {
+		return plans[a].Source < plans[b].Source
+	})
+
+	enc := json.NewEncoder(w)
+	for _, p := range plans {
+		if err := enc.Encode(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) planIssue(ctx context.Context, issue *github.Issue, opts AllOptions) (PlannedIssue, error) {
+	p := PlannedIssue{
+		Action: PlanMigrate,
+		Source: issue.GetNumber(),
+		State:  issue.GetState(),
+		Title:  issue.GetTitle(),
+	}
+	for _, l := range issue.Labels {
+		if l.GetName() == m.cfg.SkipLabel || l.GetName() == m.cfg.MigratedToLabel {
+			p.Action = PlanSkip
+			p.Reason = "label " + l.GetName()
+			return p, nil
+		}
+	}
+	if reason := lowEngagement(issue, opts); reason != "" {
+		p.Action = PlanSkip
+		p.Reason = reason
+		return p, nil
+	}
+	if opts.Export != nil && issue.GetState() == "closed" {
+		p.Action = PlanExport
+		return p, nil
+	}
+
+	comments, err := m.issueComments(ctx, issue.GetNumber())
+	if err != nil {
+		return p, err
+	}
+	p.Comments = len(comments)
+	if reason := m.securityReason(issue, comments); reason != "" && !m.cfg.AllowSecurity {
+		p.Action = PlanSecurity
+		p.Reason = reason
+		return p, nil
+	}
+	if existing, ok := m.migrated[issue.GetNumber()]; ok {
+		p.Action = PlanComplete
+		p.Reason = "already migrated to " + existing.GetHTMLURL()
+		return p, nil
+	}
+
+	for _, l := range issue.Labels {
+		if _, ok := m.syncedName(l.GetName()); !ok {
+			p.DroppedLabels = append(p.DroppedLabels, l.GetName())
+		}
+	}
+	var reason string
+	p.Labels, _, reason = m.requireLabels(m.withAddLabels(m.assertAndSyncLabels(issue.Labels)))
+	if reason != "" {
+		p.Action = PlanFail
+		p.Reason = reason
+	}
+	var valid []string
+	for _, name := range p.Labels {
+		fixed, problem := m.fixLabel(name)
+		if problem != "" && m.cfg.OnInvalidLabel == InvalidLabelFail {
+			p.Action = PlanFail
+			p.Reason = fmt.Sprintf("label %q %s", name, problem)
+		}
+		if fixed == "" {
+			p.DroppedLabels = append(p.DroppedLabels, name)
+			continue
+		}
+		valid = append(valid, fixed)
+	}
+	p.Labels = valid
+	sort.Strings(p.Labels)
+	sort.Strings(p.DroppedLabels)
+
+	switch {
+	case m.cfg.TargetMilestone != "":
+		p.Milestone = m.cfg.TargetMilestone
+	case m.cfg.SyncMilestones && issue.Milestone != nil:
+		p.Milestone = issue.Milestone.GetTitle()
+	}
+
+	recent, _ := m.filterComments(issue, comments)
+	add := func(location, s string) {
+		if term := m.internalTerm(s); term != "" {
+			p.Internal = append(p.Internal, location+": "+term)
+		}
+	}
+	add("title", issue.GetTitle())
+	add("body", issue.GetBody())
+	for _, c := range recent {
+		add(fmt.Sprintf("comment %d", c.GetID()), c.GetBody())
+	}
+	return p, nil
+}
+
+// planLabels creates the planned labels of an issue that are missing from
+// the target, recording those that could not be created as dropped
+func (m *Migrator) planLabels(ctx context.Context, issue *github.Issue, p *PlannedIssue) error {
+	labels := append([]string(nil), p.Labels...)
+	req := &github.IssueRequest{Labels: &labels}
+	dropped, _, err := m.ensureLabels(ctx, issue.Labels, req)
+	if err != nil {
+		return err
+	}
+	p.Labels = *req.Labels
+	p.DroppedLabels = append(p.DroppedLabels, dropped...)
+	sort.Strings(p.Labels)
+	sort.Strings(p.DroppedLabels)
+	return nil
+}
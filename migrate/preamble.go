@@ -0,0 +1,51 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// preambleFields are the fields available to Config.BodyPreamble
+type preambleFields struct {
+	Number    int
+	Title     string
+	URL       string
+	Author    string
+	CreatedAt string
+	State     string
+	Repo      string
+}
+
+// parsePreamble parses the BodyPreamble template and renders a sample issue
+// with it, so mistakes surface before any prompt. Empty text parses to nil.
+func parsePreamble(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	t, err := template.New("preamble").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: body preamble: %v", ErrInvalidConfig, err)
+	}
+	if err := t.Execute(&strings.Builder{}, preambleFields{}); err != nil {
+		return nil, fmt.Errorf("%w: body preamble: %v", ErrInvalidConfig, err)
+	}
+	return t, nil
+}
+
+// renderPreamble renders the preamble of a source issue
+func (m *Migrator) renderPreamble(issue *github.Issue) (string, error) {
+	var b strings.Builder
+	err := m.preamble.Execute(&b, preambleFields{
+		Number:    issue.GetNumber(),
+		Title:     issue.GetTitle(),
+		URL:       issue.GetHTMLURL(),
+		Author:    issue.GetUser().GetLogin(),
+		CreatedAt: issue.GetCreatedAt().Format("2006-01-02"),
+		State:     issue.GetState(),
+		Repo:      m.cfg.From.String(),
+	})
+	return b.String(), err
+}
@@ -0,0 +1,32 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// shortBodyLength is the most characters, ignoring surrounding space, a body
+// may have for FirstCommentAsBody to replace it
+const shortBodyLength = 20
+
+// promoteFirstComment returns issue with the first comment moved into its
+// body, with attribution, and the remaining comments. Issues whose body is
+// longer than shortBodyLength, or without comments, are returned unchanged.
+func (m *Migrator) promoteFirstComment(issue *github.Issue, comments []*github.IssueComment) (*github.Issue, []*github.IssueComment) {
+	short := strings.TrimSpace(issue.GetBody())
+	if len(comments) == 0 || utf8.RuneCountInString(short) > shortBodyLength {
+		return issue, comments
+	}
+	first := comments[0]
+	body := fmt.Sprintf("_Description from the first comment by `@%s` on %s:_\n\n%s",
+		first.GetUser().GetLogin(), first.GetCreatedAt().Format("2006-01-02"), first.GetBody())
+	if short != "" {
+		body = short + "\n\n" + body
+	}
+	promoted := *issue
+	promoted.Body = &body
+	return &promoted, comments[1:]
+}
@@ -0,0 +1,47 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v36/github"
+)
+
+func TestPromoteFirstComment(t *testing.T) {
+	created := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	first := &github.IssueComment{
+		Body:      github.String("Steps to reproduce"),
+		User:      &github.User{Login: github.String("octocat")},
+		CreatedAt: &created,
+	}
+	second := &github.IssueComment{Body: github.String("Same here")}
+	attribution := "_Description from the first comment by `@octocat` on 2021-07-01:_\n\nSteps to reproduce"
+	atLimit := strings.Repeat("x", shortBodyLength)
+	tests := []struct {
+		name     string
+		body     *string
+		comments []*github.IssueComment
+		want     string
+		rest     int
+	}{
+		{"null body", nil, []*github.IssueComment{first, second}, attribution, 1},
+		{"empty body", github.String(""), []*github.IssueComment{first, second}, attribution, 1},
+		{"body at the limit", github.String(" " + atLimit + "\n"), []*github.IssueComment{first}, atLimit + "\n\n" + attribution, 0},
+		{"body over the limit", github.String(atLimit + "x"), []*github.IssueComment{first}, atLimit + "x", 1},
+		{"no comments", nil, nil, "", 0},
+	}
+	m := New(Config{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issue := &github.Issue{Number: github.Int(1), Body: tt.body}
+			got, rest := m.promoteFirstComment(issue, tt.comments)
+			if got.GetBody() != tt.want || len(rest) != tt.rest {
+				t.Errorf("got body %q and %d comments, want %q and %d", got.GetBody(), len(rest), tt.want, tt.rest)
+			}
+			if issue.Body != tt.body {
+				t.Errorf("the source issue was modified")
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package migrate
+
+// Prompter is how a Migrator asks the user to review each issue. The CLI
+// implements it with terminal prompts and $EDITOR.
+type Prompter interface {
+	// Confirm asks a yes/no question. It returns ErrUserAborted if the user
+	// interrupts the prompt rather than answering.
+	Confirm(label string) (bool, error)
+	// Input asks for a single line of text, prefilled with def.
+	Input(label, def string) (string, error)
+	// Edit lets the user edit content and returns the result. name says what
+	// is being edited: "body", "collate", "comment" or "combined".
+	Edit(name, content string) (string, error)
+}
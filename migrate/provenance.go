@@ -0,0 +1,183 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// Every migrated body carries a hidden marker naming its source issue, so a
+// re-run can find target issues that were created before a later step failed.
+// Markers written since drift tracking also hash the source title and body.
+var provenanceRe = regexp.MustCompile(`<!-- migratron:source=(\S+)#(\d+)((?: [a-z]+=\S+)*) -->`)
+
+// provenance is what a marker records about the source of a target issue
+type provenance struct {
+	Repo   string
+	Number int
+	// Title and Body hash the source title and body as migrated, they are
+	// empty in markers written before drift tracking
+	Title string
+	Body  string
+	// Start and End are the byte offsets of the part of the target body
+	// taken unedited from the source body, and Synced hashes it. Synced is
+	// empty when a reviewer edited the body.
+	Start  int
+	End    int
+	Synced string
+}
+
+func (p provenance) marker() string {
+	s := fmt.Sprintf("<!-- migratron:source=%s#%d", p.Repo, p.Number)
+	if p.Title != "" {
+		s += fmt.Sprintf(" title=%s body=%s", p.Title, p.Body)
+	}
+	if p.Synced != "" {
+		s += fmt.Sprintf(" span=%d:%d:%s", p.Start, p.End, p.Synced)
+	}
+	return s + " -->"
+}
+
+// parseProvenance reads the marker of a migrated body
+func parseProvenance(body string) (provenance, bool) {
+	m := provenanceRe.FindStringSubmatch(body)
+	if m == nil {
+		return provenance{}, false
+	}
+	n, err := strconv.Atoi(m[2])
+	if err != nil {
+		return provenance{}, false
+	}
+	p := provenance{Repo: m[1], Number: n}
+	for _, field := range strings.Fields(m[3]) {
+		kv := strings.SplitN(field, "=", 2)
+		switch kv[0] {
+		case "title":
+			p.Title = kv[1]
+		case "body":
+			p.Body = kv[1]
+		case "span":
+			var start, end int
+			var hash string
+			if _, err := fmt.Sscanf(strings.ReplaceAll(kv[1], ":", " "), "%d %d %s", &start, &end, &hash); err == nil && start <= end {
+				p.Start, p.End, p.Synced = start, end, hash
+			}
+		}
+	}
+	return p, true
+}
+
+// ParseProvenance returns the source repo and issue number recorded in a migrated body
+func ParseProvenance(body string) (string, int, bool) {
+	p, ok := parseProvenance(body)
+	return p.Repo, p.Number, ok
+}
+
+// hashText hashes text for a provenance marker, ignoring line endings and
+// surrounding space
+func hashText(s string) string {
+	s = strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
+
+// indexMigrated maps source issue numbers to the target issues already created from them
+func (m *Migrator) indexMigrated(ctx context.Context) (map[int]*github.Issue, error) {
+	to := m.cfg.To
+	index := map[int]*github.Issue{}
+	source := m.cfg.From.String()
+	opts := &github.IssueListByRepoOptions{
+		State: "all",
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	for {
+		issues, resp, err := m.client.Issues.ListByRepo(ctx, to.Owner, to.Name, opts)
+		if err != nil {
+			return nil, newAPIError("list target issues", err)
+		}
+		for _, i := range issues {
+			repo, n, ok := ParseProvenance(i.GetBody())
+			if ok && strings.EqualFold(repo, source) {
+				index[n] = i
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return index, nil
+}
+
+// completeSource applies the source-side steps of a migration, the backlink
+// comment and the migrated label, skipping whichever are already present,
+// then removes the PruneSourceLabels from the source issue. A SourceReadOnly
+// source is left untouched.
+func (m *Migrator) completeSource(ctx context.Context, issue *github.Issue, comments []*github.IssueComment, targetURL string) error {
+	if m.cfg.SourceReadOnly {
+		return nil
+	}
+	from := m.cfg.From
+	commentBody := "Migrated to " + targetURL + "."
+	hasComment := false
+	for _, c := range comments {
+		if strings.Contains(c.GetBody(), commentBody) {
+			hasComment = true
+			break
+		}
+	}
+	if !hasComment {
+		myUser, _, err := m.source.Users.Get(ctx, m.cfg.Login)
+		if err != nil {
+			return newAPIError("get user", err)
+		}
+		comment := github.IssueComment{
+			Body: &commentBody,
+			User: myUser,
+		}
+		err = m.withSecondaryRetry(ctx, func() (err error) {
+			_, _, err = m.source.Issues.CreateComment(ctx, from.Owner, from.Name, *issue.Number, &comment)
+			return err
+		})
+		if err != nil {
+			return newAPIError("create comment", err)
+		}
+	}
+
+	present := map[string]bool{}
+	for _, l := range issue.Labels {
+		present[l.GetName()] = true
+	}
+	if !present[m.cfg.MigratedToLabel] {
+		err := m.withSecondaryRetry(ctx, func() (err error) {
+			_, _, err = m.source.Issues.AddLabelsToIssue(ctx, from.Owner, from.Name, *issue.Number, []string{m.cfg.MigratedToLabel})
+			return err
+		})
+		if err != nil {
+			return newAPIError("add labels", err)
+		}
+	}
+
+	for _, name := range m.cfg.PruneSourceLabels {
+		if !present[name] || name == m.cfg.MigratedToLabel {
+			continue
+		}
+		err := m.withSecondaryRetry(ctx, func() (err error) {
+			_, err = m.source.Issues.RemoveLabelForIssue(ctx, from.Owner, from.Name, *issue.Number, name)
+			return err
+		})
+		if err != nil {
+			return newAPIError("remove label", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-github/v36/github"
+)
+
+func TestMigrateIssueRecoversPartialFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		// fail is the source-side request that fails after the target
+		// issue was created
+		fail string
+	}{
+		{"backlink comment", "POST /repos/acme/internal/issues/1/comments"},
+		{"migrated label", "POST /repos/acme/internal/issues/1/labels"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeGitHub(t)
+			f.addIssue(testSource, &github.Issue{
+				Title: github.String("Crash on start"),
+				Body:  github.String("It crashes when started without a config file."),
+			})
+			ctx := context.Background()
+
+			// unattended, so the failure is not offered for a retry
+			f.fail[tt.fail] = true
+			_, err := newTestMigrator(t, f, Config{AutoClean: true}).MigrateIssue(ctx, 1)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("first run: got error %v, want an APIError", err)
+			}
+			if n := len(f.repo(testTarget).issues); n != 1 {
+				t.Fatalf("first run created %d target issues, want 1", n)
+			}
+
+			delete(f.fail, tt.fail)
+			res, err := newTestMigrator(t, f, Config{}).MigrateIssue(ctx, 1)
+			if err != nil {
+				t.Fatalf("re-run: %v", err)
+			}
+			if res.Status != StatusCompleted || res.Dest != 1 {
+				t.Errorf("re-run: got status %s to #%d, want %s to #1", res.Status, res.Dest, StatusCompleted)
+			}
+			if n := len(f.repo(testTarget).issues); n != 1 {
+				t.Errorf("re-run left %d target issues, want 1", n)
+			}
+
+			source := f.repo(testSource)
+			backlink := fmt.Sprintf("Migrated to https://github.com/%s/issues/1.", testTarget)
+			var backlinks int
+			for _, c := range source.comments[1] {
+				if c.GetBody() == backlink {
+					backlinks++
+				}
+			}
+			if backlinks != 1 {
+				t.Errorf("source has %d backlink comments, want 1", backlinks)
+			}
+			var migrated int
+			for _, name := range labelNames(source.issues[0].Labels) {
+				if name == DefaultMigratedToLabel {
+					migrated++
+				}
+			}
+			if migrated != 1 {
+				t.Errorf("source has the %s label %d times, want once", DefaultMigratedToLabel, migrated)
+			}
+		})
+	}
+}
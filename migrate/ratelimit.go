@@ -0,0 +1,41 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/go-github/v36/github"
+)
+
+const (
+	// maxSecondaryRetries bounds how often a call is retried after a secondary rate limit
+	maxSecondaryRetries = 5
+	// defaultSecondaryWait is used when GitHub sends no Retry-After
+	defaultSecondaryWait = time.Minute
+)
+
+// withSecondaryRetry runs a mutating call, waiting out GitHub's secondary
+// (abuse) rate limit for the Retry-After duration and retrying
+func (m *Migrator) withSecondaryRetry(ctx context.Context, call func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := call()
+		var abuse *github.AbuseRateLimitError
+		if !errors.As(err, &abuse) || attempt == maxSecondaryRetries {
+			return err
+		}
+		wait := abuse.GetRetryAfter()
+		if wait <= 0 {
+			wait = defaultSecondaryWait
+		}
+		m.printf("Hit the secondary rate limit, waiting %s before retrying\n", wait)
+		start := time.Now()
+		select {
+		case <-ctx.Done():
+			m.stats.RateLimitWait += time.Since(start)
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		m.stats.RateLimitWait += time.Since(start)
+	}
+}
@@ -0,0 +1,43 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// replicateReactions adds one reaction of every kind the source issue
+// received to the target issue. GitHub allows one reaction of a kind per
+// user, so counts are not kept, and every reaction is attributed to the
+// token's user.
+func (m *Migrator) replicateReactions(ctx context.Context, issue, target *github.Issue) error {
+	r := issue.GetReactions()
+	counts := []struct {
+		content string
+		count   int
+	}{
+		{"+1", r.GetPlusOne()},
+		{"-1", r.GetMinusOne()},
+		{"laugh", r.GetLaugh()},
+		{"confused", r.GetConfused()},
+		{"heart", r.GetHeart()},
+		{"hooray", r.GetHooray()},
+		{"rocket", r.GetRocket()},
+		{"eyes", r.GetEyes()},
+	}
+	to := m.cfg.To
+	for _, c := range counts {
+		if c.count == 0 {
+			continue
+		}
+		content := c.content
+		err := m.withSecondaryRetry(ctx, func() (err error) {
+			_, _, err = m.client.Reactions.CreateIssueReaction(ctx, to.Owner, to.Name, target.GetNumber(), content)
+			return err
+		})
+		if err != nil {
+			return newAPIError("create reaction", err)
+		}
+	}
+	return nil
+}
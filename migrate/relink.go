@@ -0,0 +1,55 @@
+package migrate
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// rewriteRefs replaces #N and org/repo#N references to migrated source issues
+// with the URL of the migrated issue. References that were already rewritten
+// are URLs, so running it again leaves the body unchanged.
+func rewriteRefs(body string, from Repo, results map[int]Result) string {
+	refRe := regexp.MustCompile(`(^|[\s(\[])((?i:` + regexp.QuoteMeta(from.String()) + `))?#(\d+)\b`)
+	return refRe.ReplaceAllStringFunc(body, func(ref string) string {
+		m := refRe.FindStringSubmatch(ref)
+		n, err := strconv.Atoi(m[3])
+		if err != nil {
+			return ref
+		}
+		res, ok := results[n]
+		if !ok {
+			return ref
+		}
+		return m[1] + res.DestURL
+	})
+}
+
+// Relink rewrites references to migrated source issues in the bodies of the
+// target issues listed in results, as read by ReadReport. It reports the
+// target issues that were edited.
+func (m *Migrator) Relink(ctx context.Context, results map[int]Result) ([]int, error) {
+	to := m.cfg.To
+	var edited []int
+	for _, res := range results {
+		issue, _, err := m.client.Issues.Get(ctx, to.Owner, to.Name, res.Dest)
+		if err != nil {
+			return edited, newAPIError("get issue", err)
+		}
+		body := rewriteRefs(issue.GetBody(), m.cfg.From, results)
+		if body == issue.GetBody() {
+			continue
+		}
+		err = m.withSecondaryRetry(ctx, func() (err error) {
+			_, _, err = m.client.Issues.Edit(ctx, to.Owner, to.Name, res.Dest, &github.IssueRequest{Body: &body})
+			return err
+		})
+		if err != nil {
+			return edited, newAPIError("edit issue", err)
+		}
+		edited = append(edited, res.Dest)
+	}
+	return edited, nil
+}
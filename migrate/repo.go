@@ -0,0 +1,28 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Repo identifies a GitHub repository
+type Repo struct {
+	Owner string
+	Name  string
+}
+
+// ParseRepo parses an org/repo pair
+func ParseRepo(s string) (Repo, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return Repo{}, fmt.Errorf("%w: %q", ErrBadRepoFormat, s)
+	}
+	return Repo{
+		Owner: parts[0],
+		Name:  parts[1],
+	}, nil
+}
+
+func (r Repo) String() string {
+	return r.Owner + "/" + r.Name
+}
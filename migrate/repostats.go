@@ -0,0 +1,69 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// RepoStats tallies the issues of a source repo to size a migration
+type RepoStats struct {
+	Total        int `json:"total"`
+	Open         int `json:"open"`
+	Closed       int `json:"closed"`
+	PullRequests int `json:"pull_requests"`
+	// SkipLabelled and MigratedLabelled count issues carrying the skip and migrated labels
+	SkipLabelled     int `json:"skip_labelled"`
+	MigratedLabelled int `json:"migrated_labelled"`
+	// Internal counts issues whose title or body holds an internal term
+	Internal int `json:"internal"`
+}
+
+// RepoStats lists every issue and pull request of the source repo and tallies
+// them. Pull requests are only counted as such.
+func (m *Migrator) RepoStats(ctx context.Context) (RepoStats, error) {
+	var stats RepoStats
+	var err error
+	if m.blocklist, err = compileBlocklist(m.cfg.Blocklist); err != nil {
+		return stats, err
+	}
+	from := m.cfg.From
+	opts := &github.IssueListByRepoOptions{
+		State:       "all",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		issues, resp, err := m.source.Issues.ListByRepo(ctx, from.Owner, from.Name, opts)
+		if err != nil {
+			return stats, newAPIError("list issues", err)
+		}
+		for _, i := range issues {
+			if i.IsPullRequest() {
+				stats.PullRequests++
+				continue
+			}
+			stats.Total++
+			if i.GetState() == "closed" {
+				stats.Closed++
+			} else {
+				stats.Open++
+			}
+			for _, l := range i.Labels {
+				switch l.GetName() {
+				case m.cfg.SkipLabel:
+					stats.SkipLabelled++
+				case m.cfg.MigratedToLabel:
+					stats.MigratedLabelled++
+				}
+			}
+			if m.scanForInternal(i.GetTitle()) || m.scanForInternal(i.GetBody()) {
+				stats.Internal++
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return stats, nil
+}
@@ -0,0 +1,394 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// Section delimiters of the combined edit document
+const (
+	titleDelimiter    = "--- TITLE ---"
+	bodyDelimiter     = "--- BODY ---"
+	commentsDelimiter = "--- COMMENTS ---"
+)
+
+func (m *Migrator) scanForInternal(s string) bool {
+	return m.internalTerm(s) != ""
+}
+
+func (m *Migrator) generateIssueRequest(ctx context.Context, issue *github.Issue, comments []*github.IssueComment) (*github.IssueRequest, error) {
+	p := m.cfg.Prompter
+	req := &github.IssueRequest{
+		Title: issue.Title,
+		Body:  issue.Body,
+	}
+
+	// Edit the title
+	editTitleLabel := "Edit Title"
+	if m.scanForInternal(issue.GetTitle()) {
+		editTitleLabel = "Issue Title Alert! Internal Terms found in title. Please be sure to edit!"
+	}
+	editTitle, err := p.Confirm(editTitleLabel)
+	if err != nil {
+		return nil, err
+	}
+	if editTitle {
+		u, err := p.Input("Update Title", issue.GetTitle())
+		if err != nil {
+			return nil, err
+		}
+		req.Title = &u
+	}
+
+	// Edit the body
+	editBodyLabel := "Edit Body"
+	if m.scanForInternal(issue.GetBody()) {
+		editBodyLabel = "Issue Body Alert! Internal Terms found in body. Please be sure to edit!"
+	}
+	editBody, err := p.Confirm(editBodyLabel)
+	if err != nil {
+		return nil, err
+	}
+	if editBody {
+		bodyString, err := m.editUntilClean("body", issue.GetBody())
+		if err != nil {
+			return nil, err
+		}
+		req.Body = &bodyString
+	}
+
+	// Sync labels
+	syncLabels, err := p.Confirm("Sync Labels")
+	if err != nil {
+		return nil, err
+	}
+	if syncLabels {
+		synced := m.assertAndSyncLabels(issue.Labels)
+		req.Labels = &synced
+	}
+
+	// Collate comments
+	collate, err := p.Confirm("Collate Comments")
+	if err != nil {
+		return nil, err
+	}
+	if collate {
+		recent, note := m.filterComments(issue, comments)
+		collated, err := m.collateComments(recent)
+		if err != nil {
+			return nil, err
+		}
+		collated = note + collated
+		if len(collated) > 0 {
+			updatedBody := req.GetBody() + m.collatedSection(issue, comments, collated)
+			req.Body = &updatedBody
+		}
+	}
+
+	if err := m.normalizeRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// generateCombinedRequest builds the issue request like generateIssueRequest, but
+// edits the title, body and collated comments in a single editor session.
+func (m *Migrator) generateCombinedRequest(ctx context.Context, issue *github.Issue, comments []*github.IssueComment) (*github.IssueRequest, error) {
+	p := m.cfg.Prompter
+	req := &github.IssueRequest{}
+
+	if m.scanForInternal(issue.GetTitle()) {
+		m.println("Issue Title Alert! Internal Terms found in title. Please be sure to edit!")
+	}
+	if m.scanForInternal(issue.GetBody()) {
+		m.println("Issue Body Alert! Internal Terms found in body. Please be sure to edit!")
+	}
+
+	// Sync labels
+	syncLabels, err := p.Confirm("Sync Labels")
+	if err != nil {
+		return nil, err
+	}
+	if syncLabels {
+		synced := m.assertAndSyncLabels(issue.Labels)
+		req.Labels = &synced
+	}
+
+	var collated string
+	collate, err := p.Confirm("Collate Comments")
+	if err != nil {
+		return nil, err
+	}
+	if collate {
+		recent, note := m.filterComments(issue, comments)
+		collated, err = m.selectComments(recent)
+		if err != nil {
+			return nil, err
+		}
+		collated = note + collated
+	}
+
+	doc := titleDelimiter + "\n" + issue.GetTitle() + "\n" +
+		bodyDelimiter + "\n" + issue.GetBody() + "\n" +
+		commentsDelimiter + "\n" + collated
+	edited, err := m.editUntilClean("combined", doc)
+	if err != nil {
+		return nil, err
+	}
+	title, body, collatedEdit, err := parseCombined(edited)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Title = &title
+	if strings.TrimSpace(collatedEdit) != "" {
+		body = body + m.collatedSection(issue, comments, collatedEdit)
+	}
+	req.Body = &body
+
+	if err := m.normalizeRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// collatedSection renders collated comments for the end of the body, inside a
+// collapsed block when the issue has more comments than CommentCollapseThreshold
+func (m *Migrator) collatedSection(issue *github.Issue, comments []*github.IssueComment, collated string) string {
+	collated = collatedStart + collated + collatedEnd
+	if m.cfg.CommentCollapseThreshold <= 0 || len(comments) <= m.cfg.CommentCollapseThreshold {
+		return "\n### Collated Context\n" + collated
+	}
+	return fmt.Sprintf("\n### Collated Context\n\nFull thread: %s\n\n<details>\n<summary>%d comments (click to expand)</summary>\n%s\n</details>\n",
+		issue.GetHTMLURL(), len(comments), collated)
+}
+
+// parseCombined splits an edited combined document back into its sections.
+// Each delimiter must appear exactly once, in order, on a line of its own.
+func parseCombined(doc string) (title, body, comments string, err error) {
+	lines := strings.Split(doc, "\n")
+	idx := map[string]int{}
+	for i, l := range lines {
+		switch strings.TrimSpace(l) {
+		case titleDelimiter, bodyDelimiter, commentsDelimiter:
+			d := strings.TrimSpace(l)
+			if _, dup := idx[d]; dup {
+				return "", "", "", fmt.Errorf("%w: %q appears more than once", ErrBadCombinedEdit, d)
+			}
+			idx[d] = i
+		}
+	}
+	for _, d := range []string{titleDelimiter, bodyDelimiter, commentsDelimiter} {
+		if _, ok := idx[d]; !ok {
+			return "", "", "", fmt.Errorf("%w: %q was removed", ErrBadCombinedEdit, d)
+		}
+	}
+	t, b, c := idx[titleDelimiter], idx[bodyDelimiter], idx[commentsDelimiter]
+	if !(t < b && b < c) {
+		return "", "", "", fmt.Errorf("%w: sections are out of order", ErrBadCombinedEdit)
+	}
+
+	title = strings.TrimSpace(strings.Join(lines[t+1:b], " "))
+	body = strings.TrimRight(strings.Join(lines[b+1:c], "\n"), "\n")
+	comments = strings.Join(lines[c+1:], "\n")
+	return title, body, comments, nil
+}
+
+// filterComments applies CommentSince and CommentAuthors to the comments of
+// issue, and returns a note on how many were omitted, if any
+func (m *Migrator) filterComments(issue *github.Issue, comments []*github.IssueComment) ([]*github.IssueComment, string) {
+	var note string
+	recent, omitted := commentsSince(comments, m.cfg.CommentSince)
+	if omitted > 0 {
+		note += fmt.Sprintf("\n_%d earlier comments omitted, see %s_\n", omitted, issue.GetHTMLURL())
+	}
+	allowed, omitted := commentsByAuthors(recent, m.cfg.CommentAuthors)
+	if omitted > 0 {
+		note += fmt.Sprintf("\n_%d comments from other authors omitted, see %s_\n", omitted, issue.GetHTMLURL())
+	}
+	return allowed, note
+}
+
+// commentsByAuthors keeps the comments written by one of authors, all of them
+// if authors is empty, and returns how many were dropped. Bots are only kept
+// when named in authors.
+func commentsByAuthors(comments []*github.IssueComment, authors []string) ([]*github.IssueComment, int) {
+	if len(authors) == 0 {
+		return comments, 0
+	}
+	var kept []*github.IssueComment
+	for _, c := range comments {
+		for _, a := range authors {
+			if strings.EqualFold(c.GetUser().GetLogin(), a) {
+				kept = append(kept, c)
+				break
+			}
+		}
+	}
+	return kept, len(comments) - len(kept)
+}
+
+// commentsSince drops comments created before since and returns how many were dropped
+func commentsSince(comments []*github.IssueComment, since time.Time) ([]*github.IssueComment, int) {
+	if since.IsZero() {
+		return comments, 0
+	}
+	var recent []*github.IssueComment
+	for _, c := range comments {
+		if !c.GetCreatedAt().Before(since) {
+			recent = append(recent, c)
+		}
+	}
+	return recent, len(comments) - len(recent)
+}
+
+// assertAndSyncLabels drops banned labels and renames the rest through the
+// label map or prefix stripping, dropping names that end up duplicated
+func (m *Migrator) assertAndSyncLabels(labels []*github.Label) []string {
+	toLabels := []string{m.cfg.MigratedFromLabel}
+	seen := map[string]bool{strings.ToLower(m.cfg.MigratedFromLabel): true}
+	for _, l := range labels {
+		name, ok := m.syncedName(l.GetName())
+		if !ok || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		toLabels = append(toLabels, name)
+	}
+	return toLabels
+}
+
+// syncedName returns the target name of a source label, or false if it is
+// banned before or after mapping or stripping its prefix. The label map takes
+// precedence over prefix stripping.
+func (m *Migrator) syncedName(name string) (string, bool) {
+	if m.banned(name) {
+		return "", false
+	}
+	if mapped, ok := m.cfg.LabelMap[name]; ok {
+		return mapped, !m.banned(mapped)
+	}
+	for _, prefix := range m.cfg.StripLabelPrefixes {
+		if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			name = strings.TrimPrefix(name, prefix)
+			break
+		}
+	}
+	return name, !m.banned(name)
+}
+
+func (m *Migrator) banned(name string) bool {
+	for _, banned := range m.cfg.BannedLabels {
+		if name == banned {
+			return true
+		}
+	}
+	return false
+}
+
+// collateComments
+func (m *Migrator) collateComments(comments []*github.IssueComment) (string, error) {
+	collated, err := m.selectComments(comments)
+	if err != nil {
+		return "", err
+	}
+	return m.editUntilClean("collate", collated)
+}
+
+// editUntilClean edits content and, while the result still holds internal
+// terms or leaves a code block of content open, warns and offers to edit it
+// again
+func (m *Migrator) editUntilClean(name, content string) (string, error) {
+	p := m.cfg.Prompter
+	// previous is what the last edit started from
+	previous := content
+	edited, err := p.Edit(name, previous)
+	if err != nil {
+		return "", err
+	}
+	for {
+		term := m.internalTerm(edited)
+		broken := m.cfg.CodeFenceGuard && brokeFence(content, edited)
+		if term == "" && !broken {
+			return edited, nil
+		}
+		if term == "" {
+			m.printf("\nAlert! The edited %s leaves a code block open, the rest of it would render as code\n", name)
+		} else if edited == previous {
+			m.printf("\nAlert! The %s was saved unchanged and still contains %q\n", name, term)
+		} else {
+			m.printf("\nAlert! The edited %s still contains %q\n", name, term)
+		}
+		again, err := p.Confirm("Edit again")
+		if err != nil {
+			return "", err
+		}
+		if !again {
+			return edited, nil
+		}
+		previous = edited
+		if edited, err = p.Edit(name, previous); err != nil {
+			return "", err
+		}
+	}
+}
+
+// selectComments prompts for each comment to carry over and renders the chosen ones with their metadata
+func (m *Migrator) selectComments(comments []*github.IssueComment) (string, error) {
+	var collated string
+	seen := map[string]bool{}
+	duplicates := 0
+	for _, comment := range comments {
+		if m.cfg.CommentDedup {
+			key := normalizeComment(comment.GetBody())
+			if seen[key] {
+				duplicates++
+				continue
+			}
+			seen[key] = true
+		}
+
+		addCommentLabel := "Add Comment"
+		if m.scanForInternal(comment.GetBody()) {
+			m.printf("\nAlert! Internal Terms found in comment. Forcing edit!")
+			addCommentLabel = "Comment Alert! Internal Terms found in comment. Please be sure to edit!"
+		}
+
+		m.printf("\nComment: %s\n", comment.GetBody())
+		addComment, err := m.cfg.Prompter.Confirm(addCommentLabel)
+		if err != nil {
+			return "", err
+		}
+		if !addComment {
+			continue
+		}
+		if m.cfg.CommentEditAll {
+			body, err := m.editUntilClean("comment", comment.GetBody())
+			if err != nil {
+				return "", err
+			}
+			edited := *comment
+			edited.Body = &body
+			comment = &edited
+		}
+
+		attributed, err := m.attribute(comment)
+		if err != nil {
+			return "", err
+		}
+		collated = collated + "\n" + attributed
+	}
+	if duplicates > 0 {
+		m.printf("\nDropped %d duplicate comments\n", duplicates)
+	}
+	return collated, nil
+}
+
+// normalizeComment reduces a comment body to a form where trivially different copies compare equal
+func normalizeComment(body string) string {
+	return strings.ToLower(strings.Join(strings.Fields(body), " "))
+}
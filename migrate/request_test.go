@@ -0,0 +1,71 @@
+package migrate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v36/github"
+)
+
+func TestSyncedName(t *testing.T) {
+	m := New(Config{
+		BannedLabels:       []string{"internal", "team-only"},
+		LabelMap:           map[string]string{"kind/bug": "bug", "area/db": "database"},
+		StripLabelPrefixes: []string{"area/", "kind/"},
+	})
+	tests := []struct {
+		name   string
+		label  string
+		want   string
+		synced bool
+	}{
+		{"unmapped", "help wanted", "help wanted", true},
+		{"mapped", "kind/bug", "bug", true},
+		{"map over prefix", "area/db", "database", true},
+		{"prefix stripped", "area/ui", "ui", true},
+		{"prefix only", "area/", "area/", true},
+		{"banned", "internal", "", false},
+		{"banned after stripping", "area/team-only", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, synced := m.syncedName(tt.label)
+			if synced != tt.synced || synced && got != tt.want {
+				t.Errorf("syncedName(%q) = %q, %t, want %q, %t", tt.label, got, synced, tt.want, tt.synced)
+			}
+		})
+	}
+}
+
+func TestAssertAndSyncLabels(t *testing.T) {
+	m := New(Config{
+		BannedLabels:       []string{"internal"},
+		LabelMap:           map[string]string{"kind/bug": "bug", "team/infra": "internal"},
+		StripLabelPrefixes: []string{"kind/", "area/"},
+	})
+	tests := []struct {
+		name   string
+		labels []string
+		want   []string
+	}{
+		{"none", nil, []string{DefaultMigratedFromLabel}},
+		{"unmapped", []string{"docs"}, []string{DefaultMigratedFromLabel, "docs"}},
+		{"mapped and stripped", []string{"kind/bug", "area/cli"}, []string{DefaultMigratedFromLabel, "bug", "cli"}},
+		{"banned", []string{"internal", "docs"}, []string{DefaultMigratedFromLabel, "docs"}},
+		{"banned after stripping", []string{"area/internal"}, []string{DefaultMigratedFromLabel}},
+		{"duplicates after syncing", []string{"bug", "kind/bug", "area/Bug"}, []string{DefaultMigratedFromLabel, "bug"}},
+		{"mapped, unmapped and banned", []string{"internal", "kind/bug", "docs"}, []string{DefaultMigratedFromLabel, "bug", "docs"}},
+		{"mapped to a banned name", []string{"team/infra", "docs"}, []string{DefaultMigratedFromLabel, "docs"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var labels []*github.Label
+			for _, name := range tt.labels {
+				labels = append(labels, &github.Label{Name: github.String(name)})
+			}
+			if got := m.assertAndSyncLabels(labels); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("assertAndSyncLabels(%q) = %q, want %q", tt.labels, got, tt.want)
+			}
+		})
+	}
+}
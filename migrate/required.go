@@ -0,0 +1,34 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// requireLabels applies RequireLabel and RequireAnyOf to the labels of a
+// target issue. It returns the labels with RequireLabel added if it was
+// missing, the labels it added, and why the issue fails RequireAnyOf if so.
+func (m *Migrator) requireLabels(labels []string) (required, added []string, reason string) {
+	has := func(name string) bool {
+		for _, l := range labels {
+			if strings.EqualFold(l, name) {
+				return true
+			}
+		}
+		return false
+	}
+	required = labels
+	if m.cfg.RequireLabel != "" && !has(m.cfg.RequireLabel) {
+		required = append(append([]string(nil), labels...), m.cfg.RequireLabel)
+		added = []string{m.cfg.RequireLabel}
+	}
+	if len(m.cfg.RequireAnyOf) == 0 {
+		return required, added, ""
+	}
+	for _, name := range m.cfg.RequireAnyOf {
+		if has(name) {
+			return required, added, ""
+		}
+	}
+	return required, added, fmt.Sprintf("none of the required labels %s apply", strings.Join(m.cfg.RequireAnyOf, ", "))
+}
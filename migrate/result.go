@@ -0,0 +1,77 @@
+package migrate
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// Status is the outcome of migrating one source issue
+type Status string
+
+const (
+	StatusMigrated  Status = "migrated"
+	StatusCompleted Status = "completed"
+	StatusDeclined  Status = "declined"
+	StatusSkipped   Status = "skipped"
+	StatusExported  Status = "exported"
+	StatusArchived  Status = "archived"
+	StatusSecurity  Status = "security"
+	StatusReview    Status = "review"
+	StatusFailed    Status = "failed"
+)
+
+// Result describes what happened to one source issue. It is also the line
+// format of report files.
+type Result struct {
+	Source    int    `json:"source"`
+	SourceURL string `json:"source_url"`
+	Dest      int    `json:"dest,omitempty"`
+	DestURL   string `json:"dest_url,omitempty"`
+	Status    Status `json:"status"`
+	Error     string `json:"error,omitempty"`
+	// DroppedLabels lists the synced labels that could not be created in the target
+	DroppedLabels []string `json:"dropped_labels,omitempty"`
+	// LabelCollisions lists the synced labels that differed from an existing target label
+	LabelCollisions []string `json:"label_collisions,omitempty"`
+	// EnforcedLabels lists the labels added to satisfy RequireLabel
+	EnforcedLabels []string `json:"enforced_labels,omitempty"`
+	// PrivateAssets lists the attachments of a private source the issue links
+	PrivateAssets []string `json:"private_assets,omitempty"`
+	// NumberNotPreserved is set when preserving numbers, the target issue
+	// did not get the source number
+	NumberNotPreserved bool `json:"number_not_preserved,omitempty"`
+}
+
+func newResult(issue *github.Issue, status Status) Result {
+	return Result{
+		Source:    issue.GetNumber(),
+		SourceURL: issue.GetHTMLURL(),
+		Status:    status,
+	}
+}
+
+// ReadReport reads the results written one JSON object per line to a report,
+// keeping those that produced a target issue, keyed by source number.
+func ReadReport(r io.Reader) (map[int]Result, error) {
+	results := map[int]Result{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var res Result
+		if err := json.Unmarshal(scanner.Bytes(), &res); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if (res.Status == StatusMigrated || res.Status == StatusCompleted) && res.DestURL != "" {
+			results[res.Source] = res
+		}
+	}
+	return results, scanner.Err()
+}
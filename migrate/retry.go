@@ -0,0 +1,62 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// Choices offered when an issue fails during an interactive run
+const (
+	failureRetry = "retry"
+	failureSkip  = "skip"
+	failureAbort = "abort"
+)
+
+// askRetry asks whether to retry a failed issue, skip it or abort the run.
+// Only API failures of interactive runs are offered, anything else aborts.
+func (m *Migrator) askRetry(issue *github.Issue, err error) (string, error) {
+	var apiErr *APIError
+	if m.cfg.AutoClean || !errors.As(err, &apiErr) {
+		return failureAbort, nil
+	}
+	m.printf("Issue %d failed: %v\n", issue.GetNumber(), err)
+	retry, err := m.cfg.Prompter.Confirm("Retry issue?")
+	if err != nil || retry {
+		return failureRetry, err
+	}
+	skip, err := m.cfg.Prompter.Confirm("Skip it and continue with the next issue?")
+	if err != nil || !skip {
+		return failureAbort, err
+	}
+	return failureSkip, nil
+}
+
+// migrateWithRetry migrates an issue, letting the user retry or skip it
+// when it fails
+func (m *Migrator) migrateWithRetry(ctx context.Context, issue *github.Issue) (Result, error) {
+	res, err := m.migrateOne(ctx, issue)
+	for err != nil {
+		action, perr := m.askRetry(issue, err)
+		switch {
+		case perr != nil:
+			return res, perr
+		case action == failureAbort:
+			return res, err
+		case action == failureSkip:
+			res = newResult(issue, StatusSkipped)
+			res.Error = "skipped after failing: " + err.Error()
+			return res, nil
+		}
+		// the failed attempt may have created the target issue, which the
+		// retry then completes instead of creating it again
+		if !m.cfg.NoTarget {
+			if m.migrated, err = m.indexMigrated(ctx); err != nil {
+				return res, err
+			}
+		}
+		res, err = m.migrateOne(ctx, issue)
+	}
+	return res, nil
+}
@@ -0,0 +1,131 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// Rollback undoes the migrations listed in results, as read by ReadReport.
+// Each target issue is closed as not planned with its provenance marker
+// removed, or deleted when del is set and the token may delete issues. The
+// backlink comment and the migrated label are removed from each source
+// issue. It asks for confirmation first and reports the source issues that
+// were rolled back.
+func (m *Migrator) Rollback(ctx context.Context, results map[int]Result, del bool) ([]int, error) {
+	if m.cfg.Prompter == nil {
+		return nil, fmt.Errorf("%w: a Prompter is required", ErrInvalidConfig)
+	}
+	sources := make([]int, 0, len(results))
+	for n := range results {
+		sources = append(sources, n)
+	}
+	sort.Ints(sources)
+
+	action := "close"
+	if del {
+		action = "delete"
+	}
+	m.printf("Will %s %d issues in %s and unmark their source issues in %s\n", action, len(sources), m.cfg.To, m.cfg.From)
+	ok, err := m.cfg.Prompter.Confirm("Roll back the migration")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrUserAborted
+	}
+
+	var rolledBack []int
+	for _, n := range sources {
+		res := results[n]
+		if err := m.rollbackTarget(ctx, res.Dest, del); err != nil {
+			return rolledBack, err
+		}
+		if err := m.rollbackSource(ctx, n, res.DestURL); err != nil {
+			return rolledBack, err
+		}
+		rolledBack = append(rolledBack, n)
+	}
+	return rolledBack, nil
+}
+
+// rollbackTarget deletes or closes a migrated target issue. Deleting falls
+// back to closing when the token lacks admin access.
+func (m *Migrator) rollbackTarget(ctx context.Context, number int, del bool) error {
+	to := m.cfg.To
+	issue, resp, err := m.client.Issues.Get(ctx, to.Owner, to.Name, number)
+	if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone) {
+		return nil
+	}
+	if err != nil {
+		return newAPIError("get issue", err)
+	}
+
+	if del {
+		err := m.graphql(ctx, m.client, `mutation($issue: ID!) {
+  deleteIssue(input: {issueId: $issue}) { clientMutationId }
+}`, map[string]interface{}{"issue": issue.GetNodeID()}, nil)
+		if err == nil {
+			return nil
+		}
+		m.printf("Could not delete issue %d, closing it instead: %v\n", number, err)
+	}
+
+	// without its marker a re-run does not take the closed issue for a migration
+	body := strings.TrimSpace(provenanceRe.ReplaceAllString(issue.GetBody(), ""))
+	if body != issue.GetBody() {
+		err = m.withSecondaryRetry(ctx, func() (err error) {
+			_, _, err = m.client.Issues.Edit(ctx, to.Owner, to.Name, number, &github.IssueRequest{Body: &body})
+			return err
+		})
+		if err != nil {
+			return newAPIError("edit issue", err)
+		}
+	}
+	if issue.GetState() == "closed" {
+		return nil
+	}
+	if err := m.closeIssue(ctx, number, StateReasonNotPlanned); err != nil {
+		return newAPIError("close issue", err)
+	}
+	return nil
+}
+
+// rollbackSource removes the backlink comment to targetURL and the migrated
+// label from a source issue
+func (m *Migrator) rollbackSource(ctx context.Context, number int, targetURL string) error {
+	from := m.cfg.From
+	comments, err := m.issueComments(ctx, number)
+	if err != nil {
+		return err
+	}
+	backlink := "Migrated to " + targetURL + "."
+	for _, c := range comments {
+		if !strings.Contains(c.GetBody(), backlink) {
+			continue
+		}
+		id := c.GetID()
+		err := m.withSecondaryRetry(ctx, func() (err error) {
+			_, err = m.source.Issues.DeleteComment(ctx, from.Owner, from.Name, id)
+			return err
+		})
+		if err != nil {
+			return newAPIError("delete comment", err)
+		}
+	}
+
+	var resp *github.Response
+	err = m.withSecondaryRetry(ctx, func() (err error) {
+		resp, err = m.source.Issues.RemoveLabelForIssue(ctx, from.Owner, from.Name, number, m.cfg.MigratedToLabel)
+		return err
+	})
+	// the label may already be gone
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return newAPIError("remove label", err)
+	}
+	return nil
+}
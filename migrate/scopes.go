@@ -0,0 +1,43 @@
+package migrate
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// RequiredScope returns the classic token scope needed to migrate issues in
+// repo: public_repo for public repos, repo for private and internal ones
+func RequiredScope(repo *github.Repository) string {
+	if repo.GetPrivate() || repo.GetVisibility() == "internal" {
+		return "repo"
+	}
+	return "public_repo"
+}
+
+// HasScope reports whether the comma separated X-OAuth-Scopes value grants
+// scope. The repo scope includes public_repo.
+func HasScope(scopes, scope string) bool {
+	for _, s := range strings.Split(scopes, ",") {
+		s = strings.TrimSpace(s)
+		if s == scope || (s == "repo" && scope == "public_repo") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkScope verifies the token scopes reported with a response for repo
+// suit its visibility. Fine-grained tokens report no scopes and are not checked.
+func checkScope(r Repo, repo *github.Repository, header http.Header) error {
+	scopes := header.Get("X-OAuth-Scopes")
+	if scopes == "" {
+		return nil
+	}
+	if need := RequiredScope(repo); !HasScope(scopes, need) {
+		return fmt.Errorf("%w: %s needs the %s scope, the token has %q", ErrInsufficientScope, r, need, scopes)
+	}
+	return nil
+}
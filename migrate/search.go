@@ -0,0 +1,53 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// maxSearchResults is the most results GitHub search returns for a query
+const maxSearchResults = 1000
+
+// searchIssues returns the source issues matching a GitHub search query,
+// newest first, waiting out the search rate limit between pages
+func (m *Migrator) searchIssues(ctx context.Context, query string, includeClosed bool) ([]*github.Issue, error) {
+	q := "repo:" + m.cfg.From.String() + " is:issue " + query
+	if !includeClosed {
+		q += " is:open"
+	}
+
+	var issues []*github.Issue
+	opts := &github.SearchOptions{
+		Sort:        "created",
+		Order:       "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		result, resp, err := m.source.Search.Issues(ctx, q, opts)
+		var rateErr *github.RateLimitError
+		if errors.As(err, &rateErr) {
+			wait := time.Until(rateErr.Rate.Reset.Time) + time.Second
+			m.printf("Hit the search rate limit, waiting %s\n", wait.Round(time.Second))
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+		if err != nil {
+			return nil, newAPIError("search issues", err)
+		}
+		if opts.Page == 0 && result.GetTotal() > maxSearchResults {
+			m.printf("Warning: %d issues match %q, GitHub search only returns the first %d\n", result.GetTotal(), q, maxSearchResults)
+		}
+		issues = append(issues, result.Issues...)
+		if resp.NextPage == 0 {
+			return issues, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
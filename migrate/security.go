@@ -0,0 +1,28 @@
+package migrate
+
+import (
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// advisoryPath appears in links to repository security advisories
+const advisoryPath = "/security/advisories/"
+
+// securityReason explains why an issue must not go public, or returns "" if it may
+func (m *Migrator) securityReason(issue *github.Issue, comments []*github.IssueComment) string {
+	for _, l := range issue.Labels {
+		if l.GetName() == m.cfg.SecurityLabel {
+			return "has the " + m.cfg.SecurityLabel + " label"
+		}
+	}
+	if strings.Contains(issue.GetBody(), advisoryPath) {
+		return "body references a security advisory"
+	}
+	for _, c := range comments {
+		if strings.Contains(c.GetBody(), advisoryPath) {
+			return "a comment references a security advisory"
+		}
+	}
+	return ""
+}
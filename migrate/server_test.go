@@ -0,0 +1,401 @@
+package migrate
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// Repos of the Migrators built by newTestMigrator
+var (
+	testSource = Repo{Owner: "acme", Name: "internal"}
+	testTarget = Repo{Owner: "acme", Name: "public"}
+)
+
+// fakeGitHub serves the part of the GitHub REST API a migration uses from
+// repos kept in memory
+type fakeGitHub struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	repos map[string]*fakeRepo
+	// fail holds requests, as "METHOD /path", answered with a server error
+	fail map[string]bool
+	// patches holds the raw body of every issue PATCH by issue path
+	patches map[string][]map[string]interface{}
+	// graphql answers POST /graphql
+	graphql http.HandlerFunc
+}
+
+type fakeRepo struct {
+	name         Repo
+	issues       []*github.Issue
+	stateReasons map[int]string
+	comments     map[int][]*github.IssueComment
+	labels       []*github.Label
+	milestones   []*github.Milestone
+	// files holds the content of the repo's files by path
+	files map[string]string
+}
+
+// newFakeGitHub starts a fakeGitHub holding the test source and target repos
+func newFakeGitHub(t *testing.T) *fakeGitHub {
+	t.Helper()
+	f := &fakeGitHub{
+		repos:   map[string]*fakeRepo{},
+		fail:    map[string]bool{},
+		patches: map[string][]map[string]interface{}{},
+	}
+	for _, r := range []Repo{testSource, testTarget} {
+		f.repos[r.String()] = &fakeRepo{name: r, stateReasons: map[int]string{}, comments: map[int][]*github.IssueComment{}, files: map[string]string{}}
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// newTestMigrator returns a Migrator from testSource to testTarget on f,
+// approving every issue unless cfg says otherwise
+func newTestMigrator(t *testing.T, f *fakeGitHub, cfg Config) *Migrator {
+	t.Helper()
+	u, err := url.Parse(f.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.HTTPClient = &http.Client{Transport: serverTransport{u}}
+	cfg.From, cfg.To = testSource, testTarget
+	if cfg.Login == "" {
+		cfg.Login = "migrator"
+	}
+	if cfg.Prompter == nil {
+		cfg.Prompter = approver{}
+	}
+	return New(cfg)
+}
+
+// serverTransport sends every request to the server at url, whatever its host
+type serverTransport struct {
+	url *url.URL
+}
+
+func (t serverTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme, r.URL.Host = t.url.Scheme, t.url.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// approver answers yes to every prompt and leaves content unedited
+type approver struct{}
+
+func (approver) Confirm(label string) (bool, error)        { return true, nil }
+func (approver) Input(label, def string) (string, error)   { return def, nil }
+func (approver) Edit(name, content string) (string, error) { return content, nil }
+
+// repo returns the repo r of f
+func (f *fakeGitHub) repo(r Repo) *fakeRepo {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.repos[r.String()]
+}
+
+// addIssue adds an issue to the repo r
+func (f *fakeGitHub) addIssue(r Repo, issue *github.Issue) *github.Issue {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.repos[r.String()].add(issue)
+}
+
+// add adds an issue numbered after the last one
+func (repo *fakeRepo) add(issue *github.Issue) *github.Issue {
+	n := len(repo.issues) + 1
+	issue.Number = &n
+	issue.HTMLURL = github.String(fmt.Sprintf("https://github.com/%s/issues/%d", repo.name, n))
+	if issue.State == nil {
+		issue.State = github.String("open")
+	}
+	repo.issues = append(repo.issues, issue)
+	return issue
+}
+
+func (f *fakeGitHub) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail[r.Method+" "+r.URL.Path] {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"message": "Server Error"}`)
+		return
+	}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Path == "/graphql" && f.graphql != nil:
+		f.graphql(w, r)
+	case len(parts) == 2 && parts[0] == "users":
+		writeJSON(w, &github.User{Login: &parts[1]})
+	case len(parts) >= 3 && parts[0] == "repos" && f.repos[parts[1]+"/"+parts[2]] != nil:
+		f.serveRepo(w, r, f.repos[parts[1]+"/"+parts[2]], parts[3:])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeGitHub) serveRepo(w http.ResponseWriter, r *http.Request, repo *fakeRepo, parts []string) {
+	route := r.Method
+	if len(parts) > 0 {
+		route += " " + parts[0]
+	}
+	var number int
+	if len(parts) > 1 && parts[0] == "issues" {
+		number, _ = strconv.Atoi(parts[1])
+		if number < 1 || number > len(repo.issues) {
+			http.NotFound(w, r)
+			return
+		}
+		route += " n"
+		if len(parts) > 2 {
+			route += " " + parts[2]
+		}
+	}
+	switch route {
+	case "GET":
+		writeJSON(w, &github.Repository{FullName: github.String(repo.name.String()), HasIssues: github.Bool(true)})
+	case "GET labels":
+		if len(parts) == 2 {
+			if l := findLabel(repo.labels, parts[1]); l != nil {
+				writeJSON(w, l)
+				return
+			}
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, repo.labels)
+	case "POST labels":
+		l := new(github.Label)
+		readJSON(r, l)
+		if findLabel(repo.labels, l.GetName()) != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message": "Validation Failed", "errors": [{"resource": "Label", "code": "already_exists", "field": "name"}]}`)
+			return
+		}
+		repo.labels = append(repo.labels, l)
+		writeJSON(w, l)
+	case "PATCH labels":
+		l := findLabel(repo.labels, parts[1])
+		if l == nil {
+			http.NotFound(w, r)
+			return
+		}
+		readJSON(r, l)
+		writeJSON(w, l)
+	case "GET milestones":
+		writeJSON(w, repo.milestones)
+	case "POST milestones":
+		ms := new(github.Milestone)
+		readJSON(r, ms)
+		ms.Number = github.Int(len(repo.milestones) + 1)
+		if ms.State == nil {
+			ms.State = github.String("open")
+		}
+		repo.milestones = append(repo.milestones, ms)
+		writeJSON(w, ms)
+	case "GET issues":
+		state := r.URL.Query().Get("state")
+		issues := []*github.Issue{}
+		for _, i := range repo.issues {
+			if state == "all" || i.GetState() == state || state == "" && i.GetState() == "open" {
+				issues = append(issues, i)
+			}
+		}
+		// issues are listed newest first unless asked otherwise. Issues
+		// given a creation time, like transferred ones, are ordered by it
+		// rather than by number.
+		asc := r.URL.Query().Get("direction") == "asc"
+		if !asc {
+			for a, b := 0, len(issues)-1; a < b; a, b = a+1, b-1 {
+				issues[a], issues[b] = issues[b], issues[a]
+			}
+		}
+		sort.SliceStable(issues, func(a, b int) bool {
+			if asc {
+				return issues[a].GetCreatedAt().Before(issues[b].GetCreatedAt())
+			}
+			return issues[a].GetCreatedAt().After(issues[b].GetCreatedAt())
+		})
+		start, end := paginate(w, r, len(issues))
+		writeJSON(w, issues[start:end])
+	case "POST issues":
+		req := new(github.IssueRequest)
+		readJSON(r, req)
+		issue := &github.Issue{Title: req.Title, Body: req.Body, State: req.State}
+		if req.Labels != nil {
+			for _, l := range *req.Labels {
+				issue.Labels = append(issue.Labels, repo.label(l))
+			}
+		}
+		if req.Milestone != nil {
+			issue.Milestone = repo.milestones[*req.Milestone-1]
+		}
+		writeJSON(w, repo.add(issue))
+	case "GET issues n":
+		f.writeIssue(w, repo, number)
+	case "PATCH issues n":
+		var raw map[string]interface{}
+		readJSON(r, &raw)
+		f.patches[r.URL.Path] = append(f.patches[r.URL.Path], raw)
+		issue := repo.issues[number-1]
+		if s, ok := raw["title"].(string); ok {
+			issue.Title = &s
+		}
+		if s, ok := raw["body"].(string); ok {
+			issue.Body = &s
+		}
+		if s, ok := raw["state"].(string); ok {
+			issue.State = &s
+		}
+		if s, ok := raw["state_reason"].(string); ok {
+			repo.stateReasons[number] = s
+		}
+		f.writeIssue(w, repo, number)
+	case "GET issues n comments":
+		comments := append([]*github.IssueComment{}, repo.comments[number]...)
+		start, end := paginate(w, r, len(comments))
+		writeJSON(w, comments[start:end])
+	case "POST issues n comments":
+		c := new(github.IssueComment)
+		readJSON(r, c)
+		c.ID = github.Int64(int64(number*1000 + len(repo.comments[number]) + 1))
+		repo.comments[number] = append(repo.comments[number], c)
+		writeJSON(w, c)
+	case "POST issues n labels":
+		var names []string
+		readJSON(r, &names)
+		issue := repo.issues[number-1]
+		for _, l := range names {
+			issue.Labels = append(issue.Labels, repo.label(l))
+		}
+		writeJSON(w, issue.Labels)
+	case "GET issues n timeline":
+		writeJSON(w, []*github.Timeline{})
+	case "GET contents":
+		repo.writeContents(w, r, strings.Join(parts[1:], "/"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// writeIssue writes an issue with its state reason, which the github
+// package does not model
+func (f *fakeGitHub) writeIssue(w http.ResponseWriter, repo *fakeRepo, number int) {
+	data, _ := json.Marshal(repo.issues[number-1])
+	var raw map[string]interface{}
+	json.Unmarshal(data, &raw)
+	if reason, ok := repo.stateReasons[number]; ok {
+		raw["state_reason"] = reason
+	}
+	writeJSON(w, raw)
+}
+
+// writeContents writes the file at p, or the listing of the directory at p
+func (repo *fakeRepo) writeContents(w http.ResponseWriter, r *http.Request, p string) {
+	if content, ok := repo.files[p]; ok {
+		writeJSON(w, &github.RepositoryContent{
+			Type:     github.String("file"),
+			Name:     github.String(path.Base(p)),
+			Path:     github.String(p),
+			Encoding: github.String("base64"),
+			Content:  github.String(base64.StdEncoding.EncodeToString([]byte(content))),
+		})
+		return
+	}
+	var dir []*github.RepositoryContent
+	for name := range repo.files {
+		if path.Dir(name) == p {
+			dir = append(dir, &github.RepositoryContent{Type: github.String("file"), Name: github.String(path.Base(name)), Path: github.String(name)})
+		}
+	}
+	if dir == nil {
+		http.NotFound(w, r)
+		return
+	}
+	sort.Slice(dir, func(i, j int) bool { return dir[i].GetName() < dir[j].GetName() })
+	writeJSON(w, dir)
+}
+
+// label returns the repo's label called name, creating it like GitHub does
+// for labels set on an issue
+func (repo *fakeRepo) label(name string) *github.Label {
+	if l := findLabel(repo.labels, name); l != nil {
+		return l
+	}
+	l := &github.Label{Name: github.String(name)}
+	repo.labels = append(repo.labels, l)
+	return l
+}
+
+// findLabel finds a label by name, ignoring case like GitHub
+func findLabel(labels []*github.Label, name string) *github.Label {
+	for _, l := range labels {
+		if strings.EqualFold(l.GetName(), name) {
+			return l
+		}
+	}
+	return nil
+}
+
+// labelNames returns the names of labels
+func labelNames(labels []*github.Label) []string {
+	names := []string{}
+	for _, l := range labels {
+		names = append(names, l.GetName())
+	}
+	return names
+}
+
+// paginate returns the bounds of the page r asks for out of n items, 30 to
+// a page unless per_page asks for up to 100, and links the next page like
+// GitHub
+func paginate(w http.ResponseWriter, r *http.Request, n int) (start, end int) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	switch {
+	case perPage < 1:
+		perPage = 30
+	case perPage > 100:
+		perPage = 100
+	}
+	start, end = (page-1)*perPage, page*perPage
+	if start > n {
+		start = n
+	}
+	if end >= n {
+		return start, n
+	}
+	next := *r.URL
+	q := next.Query()
+	q.Set("page", strconv.Itoa(page+1))
+	next.RawQuery = q.Encode()
+	w.Header().Set("Link", fmt.Sprintf(`<https://api.github.com%s>; rel="next"`, next.RequestURI()))
+	return start, end
+}
+
+func readJSON(r *http.Request, v interface{}) {
+	json.NewDecoder(r.Body).Decode(v)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
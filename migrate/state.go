@@ -0,0 +1,49 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// State reasons of closed issues. Issues closed before GitHub recorded
+// reasons have none and are treated as completed.
+const (
+	StateReasonCompleted  = "completed"
+	StateReasonNotPlanned = "not_planned"
+)
+
+// sourceStateReason returns why a closed source issue was closed. The
+// github package does not model state_reason, so the issue is read raw.
+func (m *Migrator) sourceStateReason(ctx context.Context, issue *github.Issue) (string, error) {
+	from := m.cfg.From
+	req, err := m.source.NewRequest("GET", fmt.Sprintf("repos/%s/%s/issues/%d", from.Owner, from.Name, issue.GetNumber()), nil)
+	if err != nil {
+		return "", err
+	}
+	var raw struct {
+		StateReason *string `json:"state_reason"`
+	}
+	if _, err := m.source.Do(ctx, req, &raw); err != nil {
+		return "", newAPIError("get state reason", err)
+	}
+	if raw.StateReason == nil || *raw.StateReason == "" || *raw.StateReason == "reopened" {
+		return StateReasonCompleted, nil
+	}
+	return *raw.StateReason, nil
+}
+
+// closeIssue closes a target issue with the given state reason
+func (m *Migrator) closeIssue(ctx context.Context, number int, reason string) error {
+	to := m.cfg.To
+	body := map[string]string{"state": "closed", "state_reason": reason}
+	return m.withSecondaryRetry(ctx, func() error {
+		req, err := m.client.NewRequest("PATCH", fmt.Sprintf("repos/%s/%s/issues/%d", to.Owner, to.Name, number), body)
+		if err != nil {
+			return err
+		}
+		_, err = m.client.Do(ctx, req, nil)
+		return err
+	})
+}
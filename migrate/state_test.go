@@ -0,0 +1,80 @@
+package migrate
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v36/github"
+)
+
+func TestMigrateIssueKeepsStateReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"not planned", StateReasonNotPlanned, StateReasonNotPlanned},
+		{"completed", StateReasonCompleted, StateReasonCompleted},
+		{"before state reasons", "", StateReasonCompleted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeGitHub(t)
+			f.addIssue(testSource, &github.Issue{
+				Title: github.String("Support Windows XP"),
+				Body:  github.String("Please support it."),
+				State: github.String("closed"),
+			})
+			if tt.source != "" {
+				f.repo(testSource).stateReasons[1] = tt.source
+			}
+
+			if _, err := newTestMigrator(t, f, Config{}).MigrateIssue(context.Background(), 1); err != nil {
+				t.Fatal(err)
+			}
+			patches := f.patches["/repos/acme/public/issues/1"]
+			want := []map[string]interface{}{{"state": "closed", "state_reason": tt.want}}
+			if !reflect.DeepEqual(patches, want) {
+				t.Errorf("target issue patched with %v, want %v", patches, want)
+			}
+		})
+	}
+}
+
+func TestMigrateIssueDraftClosesOnce(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   string
+		patches []map[string]interface{}
+		note    string
+	}{
+		{"open source", "open", []map[string]interface{}{{"state": "closed", "state_reason": StateReasonNotPlanned}}, "Closed and labelled pending-review"},
+		{"closed source", "closed", []map[string]interface{}{{"state": "closed", "state_reason": StateReasonCompleted}}, "Labelled pending-review"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeGitHub(t)
+			f.addIssue(testSource, &github.Issue{
+				Title: github.String("Support Windows XP"),
+				Body:  github.String("Please support it."),
+				State: github.String(tt.state),
+			})
+			f.repo(testSource).stateReasons[1] = StateReasonCompleted
+
+			cfg := Config{CreateAsDraft: true, PendingReviewLabel: "pending-review"}
+			if _, err := newTestMigrator(t, f, cfg).MigrateIssue(context.Background(), 1); err != nil {
+				t.Fatal(err)
+			}
+			patches := f.patches["/repos/acme/public/issues/1"]
+			if !reflect.DeepEqual(patches, tt.patches) {
+				t.Errorf("target issue patched with %v, want %v", patches, tt.patches)
+			}
+			comments := f.repo(testTarget).comments[1]
+			if len(comments) != 1 || !strings.HasPrefix(comments[0].GetBody(), tt.note) {
+				t.Errorf("target issue comments are %v, want one starting with %q", comments, tt.note)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package migrate
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Stats measures the work done by a Migrator
+type Stats struct {
+	// APICalls counts the HTTP requests sent to GitHub
+	APICalls int64
+	// RateLimitWait is the time spent waiting out secondary rate limits
+	RateLimitWait time.Duration
+	// Issues counts the issues that went through review
+	Issues int
+	// IssueTime is the time spent reviewing and migrating those issues
+	IssueTime time.Duration
+	// Outcomes counts the reported results by status
+	Outcomes map[Status]int
+}
+
+// AvgIssueTime is the mean time spent on each reviewed issue
+func (s Stats) AvgIssueTime() time.Duration {
+	if s.Issues == 0 {
+		return 0
+	}
+	return s.IssueTime / time.Duration(s.Issues)
+}
+
+// Add accumulates o into s
+func (s *Stats) Add(o Stats) {
+	s.APICalls += o.APICalls
+	s.RateLimitWait += o.RateLimitWait
+	s.Issues += o.Issues
+	s.IssueTime += o.IssueTime
+	if s.Outcomes == nil {
+		s.Outcomes = map[Status]int{}
+	}
+	for status, n := range o.Outcomes {
+		s.Outcomes[status] += n
+	}
+}
+
+// Stats returns what the Migrator has done so far
+func (m *Migrator) Stats() Stats {
+	var s Stats
+	s.Add(*m.stats)
+	s.APICalls = atomic.LoadInt64(&m.stats.APICalls)
+	return s
+}
+
+// countingTransport counts the requests sent through it
+type countingTransport struct {
+	base  http.RoundTripper
+	count *int64
+}
+
+func (t countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt64(t.count, 1)
+	return t.base.RoundTrip(req)
+}
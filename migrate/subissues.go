@@ -0,0 +1,166 @@
+package migrate
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// subIssuesFeature opts GraphQL requests into sub-issues
+const subIssuesFeature = "sub_issues"
+
+// relatedIssue is a parent or sub-issue of a source issue
+type relatedIssue struct {
+	Number     int    `json:"number"`
+	URL        string `json:"url"`
+	Repository struct {
+		NameWithOwner string `json:"nameWithOwner"`
+	} `json:"repository"`
+}
+
+// sourceRelations returns the parent and sub-issues of a source issue
+func (m *Migrator) sourceRelations(ctx context.Context, number int) (*relatedIssue, []relatedIssue, error) {
+	var data struct {
+		Repository struct {
+			Issue struct {
+				Parent    *relatedIssue `json:"parent"`
+				SubIssues struct {
+					Nodes []relatedIssue `json:"nodes"`
+				} `json:"subIssues"`
+			} `json:"issue"`
+		} `json:"repository"`
+	}
+	err := m.graphql(ctx, m.source, `query($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) {
+    issue(number: $number) {
+      parent { number url repository { nameWithOwner } }
+      subIssues(first: 100) { nodes { number url repository { nameWithOwner } } }
+    }
+  }
+}`, map[string]interface{}{"owner": m.cfg.From.Owner, "name": m.cfg.From.Name, "number": number}, &data, subIssuesFeature)
+	if err != nil {
+		return nil, nil, newAPIError("get sub-issues", err)
+	}
+	issue := data.Repository.Issue
+	return issue.Parent, issue.SubIssues.Nodes, nil
+}
+
+// linkSubIssues re-establishes the parent/sub-issue links of the issues
+// migrated in this run between their target issues. Links to issues that
+// were not migrated are noted in the target body instead.
+func (m *Migrator) linkSubIssues(ctx context.Context, results []Result) error {
+	targets := m.migratedTargets(results)
+	// target returns the target number of a related issue, if it was migrated
+	target := func(r relatedIssue) (int, bool) {
+		if !strings.EqualFold(r.Repository.NameWithOwner, m.cfg.From.String()) {
+			return 0, false
+		}
+		t, ok := targets[r.Number]
+		return t.Dest, ok
+	}
+	thisRun := map[int]bool{}
+	for _, res := range results {
+		if res.Status == StatusMigrated {
+			thisRun[res.Source] = true
+		}
+	}
+
+	for _, res := range results {
+		if res.Status != StatusMigrated {
+			continue
+		}
+		parent, subs, err := m.sourceRelations(ctx, res.Source)
+		if err != nil {
+			return err
+		}
+
+		var notes []string
+		if parent != nil {
+			if p, ok := target(*parent); ok {
+				if err := m.addSubIssue(ctx, p, res.Dest); err != nil {
+					return err
+				}
+			} else {
+				notes = append(notes, "Sub-issue of "+parent.URL)
+			}
+		}
+		// sub-issues migrated in this run link themselves to this issue as
+		// their parent, those migrated earlier had no parent to link to yet
+		for _, s := range subs {
+			n, ok := target(s)
+			switch {
+			case !ok:
+				notes = append(notes, "Parent of "+s.URL)
+			case !thisRun[s.Number]:
+				if err := m.addSubIssue(ctx, res.Dest, n); err != nil {
+					return err
+				}
+			}
+		}
+		if len(notes) > 0 {
+			if err := m.appendToBody(ctx, res.Dest, strings.Join(notes, "\n")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// migratedTargets maps source issue numbers to the target issues they were
+// migrated to, in this run or earlier ones, as Results holding their number
+// and URL
+func (m *Migrator) migratedTargets(results []Result) map[int]Result {
+	targets := map[int]Result{}
+	for n, issue := range m.migrated {
+		targets[n] = Result{Source: n, Dest: issue.GetNumber(), DestURL: issue.GetHTMLURL()}
+	}
+	for _, res := range results {
+		if res.Dest != 0 {
+			targets[res.Source] = res
+		}
+	}
+	return targets
+}
+
+// addSubIssue links the target issue child as a sub-issue of parent
+func (m *Migrator) addSubIssue(ctx context.Context, parent, child int) error {
+	to := m.cfg.To
+	p, _, err := m.client.Issues.Get(ctx, to.Owner, to.Name, parent)
+	if err != nil {
+		return newAPIError("get issue", err)
+	}
+	c, _, err := m.client.Issues.Get(ctx, to.Owner, to.Name, child)
+	if err != nil {
+		return newAPIError("get issue", err)
+	}
+	err = m.graphql(ctx, m.client, `mutation($parent: ID!, $child: ID!) {
+  addSubIssue(input: {issueId: $parent, subIssueId: $child}) { issue { id } }
+}`, map[string]interface{}{"parent": p.GetNodeID(), "child": c.GetNodeID()}, nil, subIssuesFeature)
+	if err != nil {
+		return newAPIError("add sub-issue", err)
+	}
+	return nil
+}
+
+// appendToBody adds text to the end of a target issue's body, before its
+// provenance marker so the marker stays last
+func (m *Migrator) appendToBody(ctx context.Context, number int, text string) error {
+	to := m.cfg.To
+	issue, _, err := m.client.Issues.Get(ctx, to.Owner, to.Name, number)
+	if err != nil {
+		return newAPIError("get issue", err)
+	}
+	body := issue.GetBody() + "\n\n" + text
+	if loc := provenanceRe.FindStringIndex(issue.GetBody()); loc != nil {
+		body = issue.GetBody()[:loc[0]] + text + "\n\n" + issue.GetBody()[loc[0]:]
+	}
+	err = m.withSecondaryRetry(ctx, func() (err error) {
+		_, _, err = m.client.Issues.Edit(ctx, to.Owner, to.Name, number, &github.IssueRequest{Body: &body})
+		return err
+	})
+	if err != nil {
+		return newAPIError("edit issue", err)
+	}
+	return nil
+}
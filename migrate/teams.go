@@ -0,0 +1,56 @@
+package migrate
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// teamAssignees expands the teams mentioned in the body of a source issue
+// through TeamMap. It returns the members that can be assigned in the target
+// and a note naming the members that can not, by team.
+func (m *Migrator) teamAssignees(ctx context.Context, issue *github.Issue) ([]string, string, error) {
+	var assignees, notes []string
+	seen := map[string]bool{}
+	for _, sub := range teamMentionRe.FindAllStringSubmatch(issue.GetBody(), -1) {
+		team := sub[2] + "/" + sub[3]
+		if seen[strings.ToLower(team)] {
+			continue
+		}
+		seen[strings.ToLower(team)] = true
+		members, ok := m.teamMembers(team)
+		if !ok {
+			continue
+		}
+		var unassignable []string
+		for _, login := range members {
+			ok, err := m.assignable(ctx, login)
+			if err != nil {
+				return nil, "", err
+			}
+			if ok {
+				assignees = append(assignees, login)
+			} else {
+				unassignable = append(unassignable, "`@"+login+"`")
+			}
+		}
+		if len(unassignable) > 0 {
+			notes = append(notes, "Members of "+team+" that could not be assigned: "+strings.Join(unassignable, ", "))
+		}
+	}
+	if len(notes) == 0 {
+		return assignees, "", nil
+	}
+	return assignees, "\n\n" + strings.Join(notes, "\n"), nil
+}
+
+// teamMembers returns the logins TeamMap expands org/team to
+func (m *Migrator) teamMembers(team string) ([]string, bool) {
+	for t, members := range m.cfg.TeamMap {
+		if strings.EqualFold(t, team) {
+			return members, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,178 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// issueTemplateDir holds the issue templates of a repo
+const issueTemplateDir = ".github/ISSUE_TEMPLATE"
+
+// issueTemplate is an issue template of the target repo: a markdown template
+// without its front matter, or an issue form rendered the way GitHub writes
+// submitted forms into the issue body
+type issueTemplate struct {
+	Name string
+	Body string
+	// Required are the lines a body must hold to follow the template, the
+	// headings of a markdown template or the required fields of a form
+	Required []string
+}
+
+// templateExts are the extensions of markdown templates and issue forms
+var templateExts = []string{".md", ".yml", ".yaml"}
+
+// loadTemplates reads the markdown issue templates and issue forms of the
+// target. A repo without a template directory has none.
+func (m *Migrator) loadTemplates(ctx context.Context) ([]issueTemplate, error) {
+	to := m.cfg.To
+	_, dir, resp, err := m.client.Repositories.GetContents(ctx, to.Owner, to.Name, issueTemplateDir, nil)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newAPIError("list issue templates", err)
+	}
+
+	var templates []issueTemplate
+	for _, f := range dir {
+		ext := path.Ext(f.GetName())
+		name := strings.TrimSuffix(f.GetName(), ext)
+		// config.yml configures the template chooser, it is not a form
+		if f.GetType() != "file" || !isTemplateExt(ext) || ext != ".md" && name == "config" {
+			continue
+		}
+		file, _, _, err := m.client.Repositories.GetContents(ctx, to.Owner, to.Name, f.GetPath(), nil)
+		if err != nil {
+			return nil, newAPIError("get issue template", err)
+		}
+		content, err := file.GetContent()
+		if err != nil {
+			return nil, err
+		}
+		if ext == ".md" {
+			body := stripFrontMatter(content)
+			templates = append(templates, issueTemplate{Name: name, Body: body, Required: headings(body)})
+			continue
+		}
+		t, err := parseForm(name, content)
+		if err != nil {
+			m.printf("Warning: skipping issue form %s of %s: %v\n", f.GetName(), to, err)
+			continue
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// isTemplateExt reports whether ext is the extension of a template or form
+func isTemplateExt(ext string) bool {
+	for _, e := range templateExts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// issueForm is the part of a GitHub issue form the migration uses
+type issueForm struct {
+	Body []struct {
+		Type       string `yaml:"type"`
+		Attributes struct {
+			Label string `yaml:"label"`
+			Value string `yaml:"value"`
+		} `yaml:"attributes"`
+		Validations struct {
+			Required bool `yaml:"required"`
+		} `yaml:"validations"`
+	} `yaml:"body"`
+}
+
+// parseForm reads an issue form. Its body is the fields as GitHub writes a
+// submitted form, a ### heading per field followed by its default value.
+// Markdown elements are only shown on the form and left out.
+func parseForm(name, content string) (issueTemplate, error) {
+	var form issueForm
+	if err := yaml.Unmarshal([]byte(content), &form); err != nil {
+		return issueTemplate{}, err
+	}
+	t := issueTemplate{Name: name}
+	var sections []string
+	for _, field := range form.Body {
+		if field.Type == "markdown" || field.Attributes.Label == "" {
+			continue
+		}
+		heading := "### " + strings.TrimSpace(field.Attributes.Label)
+		sections = append(sections, strings.TrimSpace(heading+"\n\n"+field.Attributes.Value))
+		if field.Validations.Required {
+			t.Required = append(t.Required, heading)
+		}
+	}
+	if len(sections) == 0 {
+		return issueTemplate{}, fmt.Errorf("no fields")
+	}
+	t.Body = strings.Join(sections, "\n\n")
+	return t, nil
+}
+
+// findTemplate returns the template called name, with or without its extension
+func findTemplate(templates []issueTemplate, name string) (*issueTemplate, error) {
+	if isTemplateExt(path.Ext(name)) {
+		name = strings.TrimSuffix(name, path.Ext(name))
+	}
+	for i := range templates {
+		if templates[i].Name == name {
+			return &templates[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no issue template or form %q in %s", ErrInvalidConfig, name, issueTemplateDir)
+}
+
+// stripFrontMatter removes the leading --- delimited YAML block of a template
+func stripFrontMatter(s string) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return strings.TrimSpace(s)
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return strings.TrimSpace(strings.Join(lines[i+1:], "\n"))
+		}
+	}
+	return strings.TrimSpace(s)
+}
+
+// headings returns the markdown headings of s
+func headings(s string) []string {
+	var h []string
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.TrimSpace(l); strings.HasPrefix(l, "#") {
+			h = append(h, l)
+		}
+	}
+	return h
+}
+
+// matchesTemplate reports whether body contains every required line of at least one template
+func matchesTemplate(templates []issueTemplate, body string) bool {
+	present := map[string]bool{}
+	for _, l := range strings.Split(body, "\n") {
+		present[strings.TrimSpace(l)] = true
+	}
+OUTER:
+	for _, t := range templates {
+		for _, l := range t.Required {
+			if !present[l] {
+				continue OUTER
+			}
+		}
+		return true
+	}
+	return false
+}
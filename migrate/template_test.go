@@ -0,0 +1,95 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v36/github"
+)
+
+const bugForm = `name: Bug report
+description: Report something broken
+labels: [bug]
+body:
+  - type: markdown
+    attributes:
+      value: Thanks for taking the time to report this.
+  - type: textarea
+    id: what
+    attributes:
+      label: What happened?
+    validations:
+      required: true
+  - type: input
+    id: version
+    attributes:
+      label: Version
+      value: latest
+  - type: dropdown
+    id: os
+    attributes:
+      label: Operating system
+      options: [Linux, macOS, Windows]
+    validations:
+      required: true
+`
+
+func TestParseForm(t *testing.T) {
+	got, err := parseForm("bug", bugForm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantBody := "### What happened?\n\n### Version\n\nlatest\n\n### Operating system"
+	if got.Body != wantBody {
+		t.Errorf("form body is %q, want %q", got.Body, wantBody)
+	}
+	wantRequired := []string{"### What happened?", "### Operating system"}
+	if strings.Join(got.Required, "|") != strings.Join(wantRequired, "|") {
+		t.Errorf("form requires %q, want %q", got.Required, wantRequired)
+	}
+
+	if _, err := parseForm("broken", "body: [: nope"); err == nil {
+		t.Error("parsing invalid YAML succeeded")
+	}
+}
+
+func TestTargetTemplateForm(t *testing.T) {
+	f := newFakeGitHub(t)
+	target := f.repo(testTarget)
+	target.files[issueTemplateDir+"/bug.yml"] = bugForm
+	target.files[issueTemplateDir+"/config.yml"] = "blank_issues_enabled: false\n"
+	f.addIssue(testSource, &github.Issue{Title: github.String("Crash on start"), Body: github.String("It crashes.")})
+	f.addIssue(testSource, &github.Issue{Title: github.String("Slow startup"), Body: github.String("It is slow.")})
+	ctx := context.Background()
+
+	m := newTestMigrator(t, f, Config{TargetTemplate: "bug.yml"})
+	if _, err := m.MigrateIssue(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	body := target.issues[0].GetBody()
+	if !strings.HasPrefix(body, "### What happened?\n\n### Version\n\nlatest\n\n### Operating system\n\n") {
+		t.Errorf("target body %q does not start with the form", body)
+	}
+	if !matchesTemplate(m.templates, body) {
+		t.Errorf("target body %q does not match the form it was built from", body)
+	}
+
+	var out bytes.Buffer
+	m = newTestMigrator(t, f, Config{Out: &out})
+	if _, err := m.MigrateIssue(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.templates) != 1 {
+		t.Errorf("read %d templates, want only the bug form", len(m.templates))
+	}
+	if !strings.Contains(out.String(), "issue 2 does not follow any issue template") {
+		t.Errorf("no warning for a body without the form's required fields in %q", out.String())
+	}
+
+	_, err := newTestMigrator(t, f, Config{TargetTemplate: "feature"}).MigrateIssue(ctx, 2)
+	if err == nil || !strings.Contains(err.Error(), `no issue template or form "feature"`) {
+		t.Errorf("migrating with a missing template returned %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// DefaultLabelHistoryLimit is the number of label events noted when LabelHistoryLimit is unset
+const DefaultLabelHistoryLimit = 10
+
+// listTimeline fetches every timeline event of a source issue
+func (m *Migrator) listTimeline(ctx context.Context, number int) ([]*github.Timeline, error) {
+	from := m.cfg.From
+	var events []*github.Timeline
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := m.source.Issues.ListIssueTimeline(ctx, from.Owner, from.Name, number, opts)
+		if err != nil {
+			return nil, newAPIError("list timeline", err)
+		}
+		events = append(events, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return events, nil
+}
+
+// resolutionRefs returns links to the merged pull requests, or failing that
+// the commit, that closed a source issue. Open issues have no resolution.
+// Pull requests that only mention the issue, or were closed unmerged, are
+// left out.
+func (m *Migrator) resolutionRefs(ctx context.Context, issue *github.Issue) ([]string, error) {
+	if issue.GetState() != "closed" {
+		return nil, nil
+	}
+	events, err := m.listTimeline(ctx, *issue.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []string
+	var closingCommit string
+	seen := map[string]bool{}
+	for _, e := range events {
+		switch e.GetEvent() {
+		case "cross-referenced":
+			pr := e.GetSource().GetIssue()
+			if pr == nil || !pr.IsPullRequest() || pr.GetState() != "closed" || seen[pr.GetHTMLURL()] {
+				continue
+			}
+			seen[pr.GetHTMLURL()] = true
+			merged, err := m.mergedPR(ctx, pr)
+			if err != nil {
+				return nil, err
+			}
+			if merged {
+				refs = append(refs, pr.GetHTMLURL())
+			}
+		case "closed":
+			if e.GetCommitID() != "" {
+				closingCommit = e.GetCommitID()
+			}
+		}
+	}
+	if len(refs) == 0 && closingCommit != "" {
+		refs = append(refs, fmt.Sprintf("https://%s/%s/commit/%s", m.sourceWebHost(), m.cfg.From, closingCommit))
+	}
+
+	return refs, nil
+}
+
+// mergedPR reports whether the pull request of a cross-reference was merged.
+// Pull requests in repos the source token can not read count as unmerged.
+func (m *Migrator) mergedPR(ctx context.Context, pr *github.Issue) (bool, error) {
+	owner, name := m.cfg.From.Owner, m.cfg.From.Name
+	if repo := pr.GetRepository(); repo != nil {
+		owner, name = repo.GetOwner().GetLogin(), repo.GetName()
+	}
+	got, resp, err := m.source.PullRequests.Get(ctx, owner, name, pr.GetNumber())
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, newAPIError("get pull request", err)
+	}
+	return got.GetMerged(), nil
+}
+
+// labelHistory returns the last limit labeled and unlabeled events of a
+// source issue, oldest first. Actors are code spans so they are not mentioned.
+func (m *Migrator) labelHistory(ctx context.Context, issue *github.Issue, limit int) ([]string, error) {
+	events, err := m.listTimeline(ctx, *issue.Number)
+	if err != nil {
+		return nil, err
+	}
+	var history []string
+	for _, e := range events {
+		if e.GetEvent() != "labeled" && e.GetEvent() != "unlabeled" {
+			continue
+		}
+		line := fmt.Sprintf("%s `%s` on %s", e.GetEvent(), e.GetLabel().GetName(), e.GetCreatedAt().Format("2006-01-02"))
+		if login := e.GetActor().GetLogin(); login != "" {
+			line += " by `@" + login + "`"
+		}
+		history = append(history, line)
+	}
+	if len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+	return history, nil
+}
+
+// labelHistoryNote renders label history as a list
+func labelHistoryNote(history []string) string {
+	return "\n\nLabel history:\n- " + strings.Join(history, "\n- ")
+}
@@ -0,0 +1,57 @@
+package migrate
+
+import (
+	"fmt"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// prepareContent readies a source issue and its comments for review: their
+// bodies are passed through Config.Transform, their links rewritten and the
+// private asset policy applied, in that order. It returns the attachments of
+// a private source it found. Only the transform can fail.
+func (m *Migrator) prepareContent(issue *github.Issue, comments []*github.IssueComment) (*github.Issue, []*github.IssueComment, []string, error) {
+	if m.cfg.Transform != nil {
+		var err error
+		if issue, comments, err = m.transformIssue(issue, comments); err != nil {
+			return issue, comments, nil, err
+		}
+	}
+	if len(m.cfg.URLMap) > 0 || m.cfg.RewriteInternalLinks {
+		issue, comments = m.rewriteIssueLinks(issue, comments)
+	}
+	assets := m.privateAssets(issue, comments)
+	if len(assets) == 0 {
+		return issue, comments, nil, nil
+	}
+	if m.cfg.OnPrivateAsset == AssetActionPlaceholder {
+		m.printf("Replacing %d attachments of the private source in issue %d\n", len(assets), issue.GetNumber())
+		issue, comments = m.replacePrivateAssets(issue, comments)
+	} else {
+		m.printf("Warning: issue %d links %d attachments of the private source that public viewers can not load\n", issue.GetNumber(), len(assets))
+	}
+	return issue, comments, assets, nil
+}
+
+// transformIssue returns copies of issue and its comments with their bodies
+// passed through Config.Transform
+func (m *Migrator) transformIssue(issue *github.Issue, comments []*github.IssueComment) (*github.Issue, []*github.IssueComment, error) {
+	body, err := m.cfg.Transform("body", issue.GetBody())
+	if err != nil {
+		return issue, comments, err
+	}
+	transformed := *issue
+	transformed.Body = &body
+
+	out := make([]*github.IssueComment, len(comments))
+	for i, c := range comments {
+		cb, err := m.cfg.Transform("comment", c.GetBody())
+		if err != nil {
+			return issue, comments, fmt.Errorf("comment %d: %w", c.GetID(), err)
+		}
+		tc := *c
+		tc.Body = &cb
+		out[i] = &tc
+	}
+	return &transformed, out, nil
+}
@@ -0,0 +1,77 @@
+package migrate
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-github/v36/github"
+)
+
+// issueTypesFeature opts GraphQL requests into issue types
+const issueTypesFeature = "issue_types"
+
+// loadIssueTypes returns the issue type IDs of the target org keyed by
+// lowercased name. It returns nil when the target does not support types.
+func (m *Migrator) loadIssueTypes(ctx context.Context) map[string]string {
+	var data struct {
+		Organization *struct {
+			IssueTypes struct {
+				Nodes []struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+				} `json:"nodes"`
+			} `json:"issueTypes"`
+		} `json:"organization"`
+	}
+	err := m.graphql(ctx, m.client, `query($login: String!) {
+  organization(login: $login) {
+    issueTypes(first: 100) { nodes { id name } }
+  }
+}`, map[string]interface{}{"login": m.cfg.To.Owner}, &data, issueTypesFeature)
+	if err != nil || data.Organization == nil || len(data.Organization.IssueTypes.Nodes) == 0 {
+		m.printf("%s does not support issue types, types will be recorded in the body\n", m.cfg.To)
+		return nil
+	}
+
+	types := map[string]string{}
+	for _, t := range data.Organization.IssueTypes.Nodes {
+		types[strings.ToLower(t.Name)] = t.ID
+	}
+	return types
+}
+
+// sourceIssueType returns the type name of a source issue, or "" if it has none
+func (m *Migrator) sourceIssueType(ctx context.Context, issue *github.Issue) (string, error) {
+	var data struct {
+		Node *struct {
+			IssueType *struct {
+				Name string `json:"name"`
+			} `json:"issueType"`
+		} `json:"node"`
+	}
+	err := m.graphql(ctx, m.source, `query($id: ID!) {
+  node(id: $id) { ... on Issue { issueType { name } } }
+}`, map[string]interface{}{"id": issue.GetNodeID()}, &data, issueTypesFeature)
+	if err != nil {
+		return "", newAPIError("get issue type", err)
+	}
+	if data.Node == nil || data.Node.IssueType == nil {
+		return "", nil
+	}
+	name := data.Node.IssueType.Name
+	if mapped, ok := m.cfg.TypeMap[name]; ok {
+		name = mapped
+	}
+	return name, nil
+}
+
+// setIssueType sets the type of a target issue
+func (m *Migrator) setIssueType(ctx context.Context, issue *github.Issue, typeID string) error {
+	err := m.graphql(ctx, m.client, `mutation($issue: ID!, $type: ID!) {
+  updateIssueIssueType(input: {issueId: $issue, issueTypeId: $type}) { issue { id } }
+}`, map[string]interface{}{"issue": issue.GetNodeID(), "type": typeID}, nil, issueTypesFeature)
+	if err != nil {
+		return newAPIError("set issue type", err)
+	}
+	return nil
+}